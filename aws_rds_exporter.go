@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
@@ -26,7 +27,61 @@ func (l promHTTPLogger) Println(v ...interface{}) {
 }
 
 type rdsOpts struct {
-	awsRegion string
+	awsRegion  string
+	awsEngines []string
+
+	collectEvents              bool
+	collectEventSubscriptions  bool
+	collectProxies             bool
+	collectEngineVersions      bool
+	collectParameterDrift      bool
+	collectLogFiles            bool
+	collectSlowQueries         bool
+	collectExportTasks         bool
+	collectSubnetGroups        bool
+	collectValidModifications  bool
+	collectOrderableClasses    bool
+	collectSourceRegions       bool
+	collectCloudWatch          bool
+	collectCloudWatchClusters  bool
+	collectEnhancedMonitoring  bool
+	collectPerformanceInsights bool
+	collectCost                bool
+	collectReservations        bool
+	collectHealth              bool
+	collectBackup              bool
+	collectKMS                 bool
+
+	eventsStateFile           string
+	exportTasksStateFile      string
+	engineVersionsCacheTTL    time.Duration
+	parametersCacheTTL        time.Duration
+	exportParameters          []string
+	logTailPatterns           []string
+	logTailInterval           time.Duration
+	logTailMaxBytes           int
+	logTailStateFile          string
+	slowQueriesTop            int
+	slowQueriesMaxDigests     int
+	validModificationsTTL     time.Duration
+	orderableCacheTTL         time.Duration
+	cloudWatchMetrics         []string
+	cloudWatchStatistics      []string
+	cloudWatchPeriod          time.Duration
+	cloudWatchDelay           time.Duration
+	cloudWatchEndpoint        string
+	cloudWatchClusterMetrics  []string
+	enhancedMonitoringTop     int
+	processMetrics            bool
+	performanceInsightsTop    int
+	performanceInsightsPeriod time.Duration
+	performanceInsightsDelay  time.Duration
+	priceListPath             string
+	reservationsCacheTTL      time.Duration
+	healthEndpoint            string
+	healthCacheTTL            time.Duration
+	backupCacheTTL            time.Duration
+	kmsCacheTTL               time.Duration
 }
 
 func run() int {
@@ -38,6 +93,63 @@ func run() int {
 		opts = rdsOpts{}
 	)
 	kingpin.Flag("rds.region", "AWS Region to query").Default("us-east-1").StringVar(&opts.awsRegion)
+	kingpin.Flag("rds.engine", "Only collect the instances and clusters of this engine, e.g. mysql, docdb or neptune, can be repeated").StringsVar(&opts.awsEngines)
+	kingpin.Flag("collector.events", "Collect counters from the RDS event stream").Default("false").BoolVar(&opts.collectEvents)
+	kingpin.Flag("collector.event-subscriptions", "Collect event subscription health and coverage").Default("false").BoolVar(&opts.collectEventSubscriptions)
+	kingpin.Flag("collector.proxies", "Collect RDS Proxy, target group and target health metrics").Default("false").BoolVar(&opts.collectProxies)
+	kingpin.Flag("collector.engine-versions", "Collect engine version upgrade readiness of the RDS instances").Default("false").BoolVar(&opts.collectEngineVersions)
+	kingpin.Flag("collector.parameter-drift", "Collect differences between the DB parameter groups in use and the engine defaults").Default("false").BoolVar(&opts.collectParameterDrift)
+	kingpin.Flag("collector.log-files", "Collect the log file inventory of the RDS instances").Default("false").BoolVar(&opts.collectLogFiles)
+	kingpin.Flag("collector.slow-queries", "Collect a slow query digest from the MySQL slow logs and PostgreSQL duration logs").Default("false").BoolVar(&opts.collectSlowQueries)
+	kingpin.Flag("collector.export-tasks", "Collect the progress of the snapshot exports to S3").Default("false").BoolVar(&opts.collectExportTasks)
+	kingpin.Flag("collector.subnet-groups", "Collect DB subnet group health and availability zone coverage").Default("false").BoolVar(&opts.collectSubnetGroups)
+	kingpin.Flag("collector.valid-modifications", "Collect the storage and instance class modifications valid for the RDS instances").Default("false").BoolVar(&opts.collectValidModifications)
+	kingpin.Flag("collector.orderable-classes", "Collect whether the RDS instances are still orderable as they are").Default("false").BoolVar(&opts.collectOrderableClasses)
+	kingpin.Flag("collector.source-regions", "Collect the source regions of the region and check cross-region read replicas against them").Default("false").BoolVar(&opts.collectSourceRegions)
+	kingpin.Flag("collector.cloudwatch", "Collect the CloudWatch metrics of the RDS instances").Default("false").BoolVar(&opts.collectCloudWatch)
+	kingpin.Flag("collector.cloudwatch-clusters", "Collect the CloudWatch metrics of the clusters, e.g. the Aurora VolumeBytesUsed").Default("false").BoolVar(&opts.collectCloudWatchClusters)
+	kingpin.Flag("collector.enhanced-monitoring", "Collect the Enhanced Monitoring OS metrics of the RDS instances").Default("false").BoolVar(&opts.collectEnhancedMonitoring)
+	kingpin.Flag("collector.performance-insights", "Collect the Performance Insights DB load of the RDS instances").Default("false").BoolVar(&opts.collectPerformanceInsights)
+	kingpin.Flag("collector.cost", "Collect the estimated on-demand cost of the RDS instances").Default("false").BoolVar(&opts.collectCost)
+	kingpin.Flag("collector.reservations", "Collect reserved instance purchase recommendations for the on-demand RDS instances").Default("false").BoolVar(&opts.collectReservations)
+	kingpin.Flag("collector.health", "Collect the open AWS Health scheduled changes and issues of the RDS instances and clusters").Default("false").BoolVar(&opts.collectHealth)
+	kingpin.Flag("collector.backup", "Collect the AWS Backup recovery points and jobs of the RDS instances and clusters").Default("false").BoolVar(&opts.collectBackup)
+	kingpin.Flag("collector.kms", "Collect the state of the KMS keys the RDS instances, clusters and snapshots are encrypted with").Default("false").BoolVar(&opts.collectKMS)
+
+	kingpin.Flag("events.state-file", "File to persist the RDS event cursor and counters in across restarts").Default("").StringVar(&opts.eventsStateFile)
+	kingpin.Flag("export-tasks.state-file", "File to persist the counted snapshot exports and counters in across restarts").Default("").StringVar(&opts.exportTasksStateFile)
+	kingpin.Flag("engine-versions.cache-ttl", "How long the RDS engine version catalog is cached").Default("6h").DurationVar(&opts.engineVersionsCacheTTL)
+	kingpin.Flag("parameters.cache-ttl", "How long DB parameter groups and engine default parameters are cached").Default("1h").DurationVar(&opts.parametersCacheTTL)
+	kingpin.Flag("parameters.export", "Name of a DB parameter to export as a metric for every RDS instance, can be repeated").StringsVar(&opts.exportParameters)
+	kingpin.Flag("log-tail.pattern", "Pattern to count in the error logs of the RDS instances as name=regexp, can be repeated").StringsVar(&opts.logTailPatterns)
+	kingpin.Flag("log-tail.interval", "How often the error and slow query logs are polled").Default("1m").DurationVar(&opts.logTailInterval)
+	kingpin.Flag("log-tail.max-bytes", "Maximum number of bytes downloaded per RDS instance and poll").Default("1048576").IntVar(&opts.logTailMaxBytes)
+	kingpin.Flag("log-tail.state-file", "File to persist the log markers and counters in across restarts").Default("").StringVar(&opts.logTailStateFile)
+	kingpin.Flag("slow-queries.top", "Number of slow query digests exported per RDS instance").Default("20").IntVar(&opts.slowQueriesTop)
+	kingpin.Flag("slow-queries.max-digests", "Maximum number of slow query digests kept per RDS instance").Default("1000").IntVar(&opts.slowQueriesMaxDigests)
+	kingpin.Flag("valid-modifications.cache-ttl", "How long the valid modifications of the RDS instances are cached").Default("6h").DurationVar(&opts.validModificationsTTL)
+	kingpin.Flag("orderable-classes.cache-ttl", "How long the orderable instance options are cached").Default("6h").DurationVar(&opts.orderableCacheTTL)
+	kingpin.Flag("cloudwatch.metric", "CloudWatch metric of the RDS instances to collect, can be repeated").Default(
+		"CPUUtilization", "FreeStorageSpace", "FreeableMemory", "DatabaseConnections", "ReadIOPS", "WriteIOPS", "ReplicaLag", "BurstBalance",
+	).StringsVar(&opts.cloudWatchMetrics)
+	kingpin.Flag("cloudwatch.statistic", "CloudWatch statistic to collect, e.g. Average, Maximum or p99, can be repeated").Default("Average").StringsVar(&opts.cloudWatchStatistics)
+	kingpin.Flag("cloudwatch.period", "Period of the CloudWatch statistics").Default("5m").DurationVar(&opts.cloudWatchPeriod)
+	kingpin.Flag("cloudwatch.delay", "How far behind now the CloudWatch statistics are read, to let CloudWatch publish them").Default("5m").DurationVar(&opts.cloudWatchDelay)
+	kingpin.Flag("cloudwatch.endpoint", "CloudWatch endpoint to use instead of the regional one").Default("").StringVar(&opts.cloudWatchEndpoint)
+	kingpin.Flag("cloudwatch.cluster-metric", "CloudWatch metric of the clusters to collect, can be repeated").Default(
+		"VolumeBytesUsed", "VolumeReadIOPs", "VolumeWriteIOPs", "AuroraReplicaLagMaximum", "ServerlessDatabaseCapacity",
+	).StringsVar(&opts.cloudWatchClusterMetrics)
+	kingpin.Flag("enhanced-monitoring.top", "Number of processes served per RDS instance on /enhanced-monitoring/processes").Default("20").IntVar(&opts.enhancedMonitoringTop)
+	kingpin.Flag("enhanced-monitoring.process-metrics", "Export the CPU and memory usage of the 5 processes using the most CPU per RDS instance").Default("false").BoolVar(&opts.processMetrics)
+	kingpin.Flag("performance-insights.top", "Number of wait events and SQL digests exported per RDS instance, at most 25").Default("10").IntVar(&opts.performanceInsightsTop)
+	kingpin.Flag("performance-insights.period", "Period the Performance Insights DB load is averaged over, one of 1s, 1m, 5m, 1h or 24h").Default("1m").DurationVar(&opts.performanceInsightsPeriod)
+	kingpin.Flag("performance-insights.delay", "How far behind now the Performance Insights DB load is read, to let Performance Insights publish it").Default("1m").DurationVar(&opts.performanceInsightsDelay)
+	kingpin.Flag("cost.price-list", "AWS Price List bulk JSON offer file for RDS, or CSV file, to estimate the cost from").Default("").StringVar(&opts.priceListPath)
+	kingpin.Flag("reservations.cache-ttl", "How long the reserved instance offerings are cached").Default("24h").DurationVar(&opts.reservationsCacheTTL)
+	kingpin.Flag("health.endpoint", "AWS Health endpoint to use instead of the global one").Default("").StringVar(&opts.healthEndpoint)
+	kingpin.Flag("health.cache-ttl", "How long the AWS Health events are cached").Default("5m").DurationVar(&opts.healthCacheTTL)
+	kingpin.Flag("backup.cache-ttl", "How long the AWS Backup recovery points and jobs are cached").Default("15m").DurationVar(&opts.backupCacheTTL)
+	kingpin.Flag("kms.cache-ttl", "How long the KMS key states are cached").Default("15m").DurationVar(&opts.kmsCacheTTL)
 
 	promlogConfig := &promlog.Config{}
 	flag.AddFlags(kingpin.CommandLine, promlogConfig)
@@ -48,7 +160,7 @@ func run() int {
 	fmt.Printf("Starting aws_rds_exporter...")
 	fmt.Printf("\n")
 
-	exporter, err := collector.NewExporter(opts.awsRegion)
+	exporter, err := collector.NewExporter(opts.awsRegion, opts.awsEngines)
 
 	if err != nil {
 		fmt.Printf("Error with rds client")
@@ -57,6 +169,197 @@ func run() int {
 
 	prometheus.MustRegister(exporter)
 
+	rdsClient, err := collector.NewRDSClient(opts.awsRegion, opts.awsEngines)
+	if err != nil {
+		level.Error(logger).Log("msg", "Error creating rds client", "err", err)
+		return 1
+	}
+
+	if opts.collectEvents {
+		events, err := collector.NewEventsCollector(rdsClient, opts.awsRegion, opts.eventsStateFile, logger)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error creating events collector", "err", err)
+			return 1
+		}
+		prometheus.MustRegister(events)
+	}
+
+	if opts.collectEventSubscriptions {
+		prometheus.MustRegister(collector.NewEventSubscriptionsCollector(rdsClient, opts.awsRegion, logger))
+	}
+
+	if opts.collectProxies {
+		prometheus.MustRegister(collector.NewProxyCollector(rdsClient, opts.awsRegion, logger))
+	}
+
+	if opts.collectEngineVersions {
+		prometheus.MustRegister(collector.NewEngineVersionsCollector(rdsClient, opts.awsRegion, opts.engineVersionsCacheTTL, logger))
+	}
+
+	if opts.collectParameterDrift {
+		drift := collector.NewParameterDriftCollector(rdsClient, opts.awsRegion, opts.parametersCacheTTL, logger)
+		prometheus.MustRegister(drift)
+		http.Handle("/parameters/drift", drift)
+	}
+
+	if len(opts.exportParameters) > 0 {
+		prometheus.MustRegister(collector.NewParameterValuesCollector(rdsClient, opts.awsRegion, opts.exportParameters, opts.parametersCacheTTL, logger))
+	}
+
+	if opts.collectLogFiles {
+		prometheus.MustRegister(collector.NewLogFilesCollector(rdsClient, opts.awsRegion, logger))
+	}
+
+	if len(opts.logTailPatterns) > 0 {
+		logTail, err := collector.NewLogTailCollector(rdsClient, opts.awsRegion, opts.logTailPatterns, opts.logTailMaxBytes, opts.logTailStateFile, logger)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error creating log tail collector", "err", err)
+			return 1
+		}
+		prometheus.MustRegister(logTail)
+		go logTail.Run(opts.logTailInterval)
+	}
+
+	if opts.collectSlowQueries {
+		slowQueries := collector.NewSlowQueriesCollector(rdsClient, opts.awsRegion, opts.slowQueriesTop, opts.slowQueriesMaxDigests, opts.logTailMaxBytes, logger)
+		prometheus.MustRegister(slowQueries)
+		http.Handle("/slow-queries", slowQueries)
+		go slowQueries.Run(opts.logTailInterval)
+	}
+
+	if opts.collectExportTasks {
+		exportTasks, err := collector.NewExportTasksCollector(rdsClient, opts.awsRegion, opts.exportTasksStateFile, logger)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error creating export tasks collector", "err", err)
+			return 1
+		}
+		prometheus.MustRegister(exportTasks)
+	}
+
+	if opts.collectSubnetGroups {
+		prometheus.MustRegister(collector.NewSubnetGroupsCollector(rdsClient, opts.awsRegion, logger))
+	}
+
+	if opts.collectValidModifications {
+		prometheus.MustRegister(collector.NewValidModificationsCollector(rdsClient, opts.awsRegion, opts.validModificationsTTL, logger))
+	}
+
+	if opts.collectOrderableClasses {
+		prometheus.MustRegister(collector.NewOrderableClassesCollector(rdsClient, opts.awsRegion, opts.orderableCacheTTL, logger))
+	}
+
+	if opts.collectSourceRegions {
+		prometheus.MustRegister(collector.NewSourceRegionsCollector(rdsClient, opts.awsRegion, logger))
+	}
+
+	if opts.collectEnhancedMonitoring {
+		logsClient, err := collector.NewCloudWatchLogsClient(opts.awsRegion)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error creating cloudwatch logs client", "err", err)
+			return 1
+		}
+		enhancedMonitoring, err := collector.NewEnhancedMonitoringCollector(
+			rdsClient, logsClient, opts.awsRegion, opts.enhancedMonitoringTop, opts.processMetrics, logger,
+		)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error creating enhanced monitoring collector", "err", err)
+			return 1
+		}
+		prometheus.MustRegister(enhancedMonitoring)
+		http.Handle("/enhanced-monitoring/processes", enhancedMonitoring)
+	}
+
+	if opts.collectPerformanceInsights {
+		piClient, err := collector.NewPIClient(opts.awsRegion)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error creating performance insights client", "err", err)
+			return 1
+		}
+		performanceInsights, err := collector.NewPerformanceInsightsCollector(
+			rdsClient, piClient, opts.awsRegion, opts.performanceInsightsTop, opts.performanceInsightsPeriod, opts.performanceInsightsDelay, logger,
+		)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error creating performance insights collector", "err", err)
+			return 1
+		}
+		prometheus.MustRegister(performanceInsights)
+	}
+
+	if opts.collectCost {
+		cost, err := collector.NewCostCollector(rdsClient, opts.awsRegion, opts.priceListPath, logger)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error creating cost collector", "err", err)
+			return 1
+		}
+		prometheus.MustRegister(cost)
+		prometheus.MustRegister(collector.NewInstanceTagsCollector(rdsClient, opts.awsRegion, logger))
+	}
+
+	if opts.collectReservations {
+		reservations, err := collector.NewReservationsCollector(rdsClient, opts.awsRegion, opts.priceListPath, opts.reservationsCacheTTL, logger)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error creating reservations collector", "err", err)
+			return 1
+		}
+		prometheus.MustRegister(reservations)
+		http.Handle("/reservations/recommendations", reservations)
+	}
+
+	if opts.collectHealth {
+		healthClient, err := collector.NewHealthClient(opts.healthEndpoint)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error creating health client", "err", err)
+			return 1
+		}
+		prometheus.MustRegister(collector.NewHealthCollector(healthClient, opts.awsRegion, opts.healthCacheTTL, logger))
+	}
+
+	if opts.collectBackup {
+		backupClient, err := collector.NewBackupClient(opts.awsRegion)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error creating backup client", "err", err)
+			return 1
+		}
+		prometheus.MustRegister(collector.NewBackupCollector(rdsClient, backupClient, opts.awsRegion, opts.backupCacheTTL, logger))
+	}
+
+	if opts.collectKMS {
+		kmsClient, err := collector.NewKMSClient(opts.awsRegion)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error creating kms client", "err", err)
+			return 1
+		}
+		prometheus.MustRegister(collector.NewKMSCollector(rdsClient, kmsClient, opts.awsRegion, opts.kmsCacheTTL, logger))
+	}
+
+	if opts.collectCloudWatch || opts.collectCloudWatchClusters {
+		cwClient, err := collector.NewCloudWatchClient(opts.awsRegion, opts.cloudWatchEndpoint)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error creating cloudwatch client", "err", err)
+			return 1
+		}
+		if opts.collectCloudWatch {
+			cloudWatch, err := collector.NewCloudWatchCollector(
+				rdsClient, cwClient, opts.awsRegion, opts.cloudWatchMetrics, opts.cloudWatchStatistics, opts.cloudWatchPeriod, opts.cloudWatchDelay, logger,
+			)
+			if err != nil {
+				level.Error(logger).Log("msg", "Error creating cloudwatch collector", "err", err)
+				return 1
+			}
+			prometheus.MustRegister(cloudWatch)
+		}
+		if opts.collectCloudWatchClusters {
+			cloudWatchClusters, err := collector.NewCloudWatchClustersCollector(
+				rdsClient, cwClient, opts.awsRegion, opts.cloudWatchClusterMetrics, opts.cloudWatchStatistics, opts.cloudWatchPeriod, opts.cloudWatchDelay, logger,
+			)
+			if err != nil {
+				level.Error(logger).Log("msg", "Error creating cloudwatch clusters collector", "err", err)
+				return 1
+			}
+			prometheus.MustRegister(cloudWatchClusters)
+		}
+	}
+
 	http.Handle(*metricsPath,
 		promhttp.InstrumentMetricHandler(
 			prometheus.DefaultRegisterer,
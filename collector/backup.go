@@ -0,0 +1,269 @@
+package collector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/backup"
+	"github.com/aws/aws-sdk-go/service/backup/backupiface"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const (
+	backupResourceInstance = "instance"
+	backupResourceCluster  = "cluster"
+)
+
+// Metrics descriptions
+var (
+	backupLabels = []string{"region", "resource", "resource_type"}
+
+	backupRecoveryPoints = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "backup", "recovery_points"),
+		"Number of completed AWS Backup recovery points of the RDS instance or cluster",
+		backupLabels,
+		nil,
+	)
+
+	backupRecoveryPointsSize = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "backup", "recovery_points_size_bytes"),
+		"Total size in bytes of the completed AWS Backup recovery points of the RDS instance or cluster",
+		backupLabels,
+		nil,
+	)
+
+	backupNewestRecoveryPointAge = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "backup", "newest_recovery_point_age_seconds"),
+		"Age of the newest completed AWS Backup recovery point of the RDS instance or cluster",
+		backupLabels,
+		nil,
+	)
+
+	backupLastJob = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "backup", "last_job_info"),
+		"State of the newest AWS Backup job of the RDS instance or cluster",
+		[]string{"region", "resource", "resource_type", "state"},
+		nil,
+	)
+
+	backupLastJobTimestamp = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "backup", "last_job_timestamp_seconds"),
+		"Creation of the newest AWS Backup job of the RDS instance or cluster",
+		backupLabels,
+		nil,
+	)
+)
+
+// BackupClient is a wrapper for AWS Backup client that implements helpers to get RDS backups
+type BackupClient struct {
+	client backupiface.BackupAPI
+}
+
+// BackupGatherer is the interface that implements the methods required to gather AWS Backup data
+type BackupGatherer interface {
+	GetRecoveryPoints(resourceArn string) ([]*types.RecoveryPoint, error)
+	GetLastBackupJob(resourceArn string) (*types.BackupJob, error)
+}
+
+// BackupResourceGatherer is the interface that implements the methods required to gather the resources AWS Backup protects
+type BackupResourceGatherer interface {
+	RDSGatherer
+	ClusterGatherer
+}
+
+// NewBackupClient will return an initialized BackupClient
+func NewBackupClient(awsRegion string) (*BackupClient, error) {
+	// Create AWS session
+	s := session.New(&aws.Config{Region: aws.String(awsRegion)})
+	if s == nil {
+		return nil, fmt.Errorf("error creating aws session")
+	}
+
+	return &BackupClient{
+		client: backup.New(s),
+	}, nil
+}
+
+// GetRecoveryPoints will get the recovery points of the resource from the AWS Backup API
+func (e *BackupClient) GetRecoveryPoints(resourceArn string) ([]*types.RecoveryPoint, error) {
+	rps := []*types.RecoveryPoint{}
+	params := &backup.ListRecoveryPointsByResourceInput{
+		ResourceArn: aws.String(resourceArn),
+	}
+
+	err := e.client.ListRecoveryPointsByResourcePages(params, func(page *backup.ListRecoveryPointsByResourceOutput, lastPage bool) bool {
+		for _, rp := range page.RecoveryPoints {
+			rps = append(rps, &types.RecoveryPoint{
+				Arn:          aws.StringValue(rp.RecoveryPointArn),
+				Vault:        aws.StringValue(rp.BackupVaultName),
+				Status:       aws.StringValue(rp.Status),
+				CreationDate: aws.TimeValue(rp.CreationDate),
+				Size:         float64(aws.Int64Value(rp.BackupSizeBytes)),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rps, nil
+}
+
+// GetLastBackupJob will get the newest backup job of the resource from the
+// AWS Backup API, or nil if it has none
+func (e *BackupClient) GetLastBackupJob(resourceArn string) (*types.BackupJob, error) {
+	var last *types.BackupJob
+	params := &backup.ListBackupJobsInput{
+		ByResourceArn: aws.String(resourceArn),
+	}
+
+	err := e.client.ListBackupJobsPages(params, func(page *backup.ListBackupJobsOutput, lastPage bool) bool {
+		for _, j := range page.BackupJobs {
+			created := aws.TimeValue(j.CreationDate)
+			if last != nil && !created.After(last.CreationDate) {
+				continue
+			}
+			last = &types.BackupJob{
+				ID:           aws.StringValue(j.BackupJobId),
+				State:        aws.StringValue(j.State),
+				CreationDate: created,
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return last, nil
+}
+
+// NewBackupCollector returns a collector for the AWS Backup coverage of the
+// RDS instances and clusters. Aurora instances are skipped, AWS Backup
+// protects their cluster. Backups are cached for cacheTTL.
+func NewBackupCollector(rdsClient BackupResourceGatherer, client BackupGatherer, awsRegion string, cacheTTL time.Duration, logger log.Logger) *backupCollector {
+	return &backupCollector{
+		rdsClient: rdsClient,
+		client:    client,
+		region:    awsRegion,
+		cache:     newTTLCache(cacheTTL),
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+type backupCollector struct {
+	rdsClient BackupResourceGatherer
+	client    BackupGatherer
+	region    string
+	cache     *ttlCache
+	logger    log.Logger
+	now       func() time.Time
+}
+
+// backupResource is an instance or cluster AWS Backup can protect
+type backupResource struct {
+	identifier   string
+	resourceType string
+	arn          string
+}
+
+// backups are the recovery points and last job of a resource
+type backups struct {
+	recoveryPoints []*types.RecoveryPoint
+	lastJob        *types.BackupJob
+}
+
+// Describe describes the metrics exported by the backup collector. It
+// implements prometheus.Collector.
+func (c *backupCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- backupRecoveryPoints
+	ch <- backupRecoveryPointsSize
+	ch <- backupNewestRecoveryPointAge
+	ch <- backupLastJob
+	ch <- backupLastJobTimestamp
+}
+
+// Collect fetches the AWS Backup recovery points and jobs of every RDS
+// instance and cluster and delivers them as Prometheus metrics. It
+// implements prometheus.Collector
+func (c *backupCollector) Collect(ch chan<- prometheus.Metric) {
+	resources := []backupResource{}
+
+	rs, err := c.rdsClient.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+	for _, r := range rs {
+		if r.Cluster != "" || r.Arn == "" {
+			continue
+		}
+		resources = append(resources, backupResource{r.Identifier, backupResourceInstance, r.Arn})
+	}
+
+	cs, err := c.rdsClient.GetRDSClusters()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS clusters", "err", err)
+		return
+	}
+	for _, cluster := range cs {
+		if cluster.Arn == "" {
+			continue
+		}
+		resources = append(resources, backupResource{cluster.Identifier, backupResourceCluster, cluster.Arn})
+	}
+
+	for _, res := range resources {
+		v, err := c.cache.get(res.arn, func() (interface{}, error) {
+			rps, err := c.client.GetRecoveryPoints(res.arn)
+			if err != nil {
+				return nil, err
+			}
+			job, err := c.client.GetLastBackupJob(res.arn)
+			if err != nil {
+				return nil, err
+			}
+			return &backups{recoveryPoints: rps, lastJob: job}, nil
+		})
+		if err != nil {
+			level.Error(c.logger).Log("msg", "Error getting AWS Backup recovery points", "resource", res.identifier, "err", err)
+			continue
+		}
+		b := v.(*backups)
+
+		count, size := 0.0, 0.0
+		var newest time.Time
+		for _, rp := range b.recoveryPoints {
+			if rp.Status != backup.RecoveryPointStatusCompleted {
+				continue
+			}
+			count++
+			size += rp.Size
+			if rp.CreationDate.After(newest) {
+				newest = rp.CreationDate
+			}
+		}
+
+		ch <- prometheus.MustNewConstMetric(backupRecoveryPoints, prometheus.GaugeValue, count, c.region, res.identifier, res.resourceType)
+		ch <- prometheus.MustNewConstMetric(backupRecoveryPointsSize, prometheus.GaugeValue, size, c.region, res.identifier, res.resourceType)
+		if !newest.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				backupNewestRecoveryPointAge, prometheus.GaugeValue, c.now().Sub(newest).Seconds(), c.region, res.identifier, res.resourceType,
+			)
+		}
+		if b.lastJob != nil {
+			ch <- prometheus.MustNewConstMetric(backupLastJob, prometheus.GaugeValue, 1, c.region, res.identifier, res.resourceType, b.lastJob.State)
+			ch <- prometheus.MustNewConstMetric(
+				backupLastJobTimestamp, prometheus.GaugeValue, float64(b.lastJob.CreationDate.Unix()), c.region, res.identifier, res.resourceType,
+			)
+		}
+	}
+}
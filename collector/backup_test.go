@@ -0,0 +1,103 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/backup"
+	"github.com/aws/aws-sdk-go/service/backup/backupiface"
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+// fakeBackupAPI serves the recovery points and backup jobs per resource ARN
+type fakeBackupAPI struct {
+	backupiface.BackupAPI
+	recoveryPoints map[string][]*backup.RecoveryPointByResource
+	jobs           map[string][]*backup.Job
+}
+
+func (f *fakeBackupAPI) ListRecoveryPointsByResourcePages(input *backup.ListRecoveryPointsByResourceInput, fn func(*backup.ListRecoveryPointsByResourceOutput, bool) bool) error {
+	fn(&backup.ListRecoveryPointsByResourceOutput{RecoveryPoints: f.recoveryPoints[aws.StringValue(input.ResourceArn)]}, true)
+	return nil
+}
+
+func (f *fakeBackupAPI) ListBackupJobsPages(input *backup.ListBackupJobsInput, fn func(*backup.ListBackupJobsOutput, bool) bool) error {
+	fn(&backup.ListBackupJobsOutput{BackupJobs: f.jobs[aws.StringValue(input.ByResourceArn)]}, true)
+	return nil
+}
+
+func TestBackupCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false,
+		types.DBInstance{Identifier: "db-1", Arn: "arn:aws:rds:us-east-1:123456789012:db:db-1"},
+		types.DBInstance{Identifier: "db-2", Arn: "arn:aws:rds:us-east-1:123456789012:db:db-2"},
+		types.DBInstance{Identifier: "aurora-1-a", Arn: "arn:aws:rds:us-east-1:123456789012:db:aurora-1-a", Cluster: "aurora-1"},
+	)
+	awsMock.MockDescribeDBClustersPages(t, mockRDS, false,
+		types.DBCluster{Identifier: "aurora-1", Arn: "arn:aws:rds:us-east-1:123456789012:cluster:aurora-1"},
+	)
+
+	now := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+	api := &fakeBackupAPI{
+		recoveryPoints: map[string][]*backup.RecoveryPointByResource{
+			"arn:aws:rds:us-east-1:123456789012:db:db-1": {
+				{Status: aws.String("COMPLETED"), CreationDate: aws.Time(now.Add(-2 * time.Hour)), BackupSizeBytes: aws.Int64(100)},
+				{Status: aws.String("COMPLETED"), CreationDate: aws.Time(now.Add(-26 * time.Hour)), BackupSizeBytes: aws.Int64(200)},
+				{Status: aws.String("PARTIAL"), CreationDate: aws.Time(now.Add(-1 * time.Hour)), BackupSizeBytes: aws.Int64(50)},
+			},
+			"arn:aws:rds:us-east-1:123456789012:cluster:aurora-1": {
+				{Status: aws.String("COMPLETED"), CreationDate: aws.Time(now.Add(-time.Hour)), BackupSizeBytes: aws.Int64(1000)},
+			},
+		},
+		jobs: map[string][]*backup.Job{
+			"arn:aws:rds:us-east-1:123456789012:db:db-1": {
+				{BackupJobId: aws.String("job-1"), State: aws.String("COMPLETED"), CreationDate: aws.Time(now.Add(-26 * time.Hour))},
+				{BackupJobId: aws.String("job-2"), State: aws.String("FAILED"), CreationDate: aws.Time(now.Add(-1 * time.Hour))},
+			},
+			"arn:aws:rds:us-east-1:123456789012:cluster:aurora-1": {
+				{BackupJobId: aws.String("job-3"), State: aws.String("COMPLETED"), CreationDate: aws.Time(now.Add(-time.Hour))},
+			},
+		},
+	}
+
+	c := NewBackupCollector(&RDSClient{client: mockRDS}, &BackupClient{client: api}, "us-east-1", time.Hour, log.NewNopLogger())
+	c.now = func() time.Time { return now }
+
+	want := `
+# HELP aws_rds_backup_last_job_info State of the newest AWS Backup job of the RDS instance or cluster
+# TYPE aws_rds_backup_last_job_info gauge
+aws_rds_backup_last_job_info{region="us-east-1",resource="aurora-1",resource_type="cluster",state="COMPLETED"} 1
+aws_rds_backup_last_job_info{region="us-east-1",resource="db-1",resource_type="instance",state="FAILED"} 1
+# HELP aws_rds_backup_last_job_timestamp_seconds Creation of the newest AWS Backup job of the RDS instance or cluster
+# TYPE aws_rds_backup_last_job_timestamp_seconds gauge
+aws_rds_backup_last_job_timestamp_seconds{region="us-east-1",resource="aurora-1",resource_type="cluster"} 1.6042284e+09
+aws_rds_backup_last_job_timestamp_seconds{region="us-east-1",resource="db-1",resource_type="instance"} 1.6042284e+09
+# HELP aws_rds_backup_newest_recovery_point_age_seconds Age of the newest completed AWS Backup recovery point of the RDS instance or cluster
+# TYPE aws_rds_backup_newest_recovery_point_age_seconds gauge
+aws_rds_backup_newest_recovery_point_age_seconds{region="us-east-1",resource="aurora-1",resource_type="cluster"} 3600
+aws_rds_backup_newest_recovery_point_age_seconds{region="us-east-1",resource="db-1",resource_type="instance"} 7200
+# HELP aws_rds_backup_recovery_points Number of completed AWS Backup recovery points of the RDS instance or cluster
+# TYPE aws_rds_backup_recovery_points gauge
+aws_rds_backup_recovery_points{region="us-east-1",resource="aurora-1",resource_type="cluster"} 1
+aws_rds_backup_recovery_points{region="us-east-1",resource="db-1",resource_type="instance"} 2
+aws_rds_backup_recovery_points{region="us-east-1",resource="db-2",resource_type="instance"} 0
+# HELP aws_rds_backup_recovery_points_size_bytes Total size in bytes of the completed AWS Backup recovery points of the RDS instance or cluster
+# TYPE aws_rds_backup_recovery_points_size_bytes gauge
+aws_rds_backup_recovery_points_size_bytes{region="us-east-1",resource="aurora-1",resource_type="cluster"} 1000
+aws_rds_backup_recovery_points_size_bytes{region="us-east-1",resource="db-1",resource_type="instance"} 300
+aws_rds_backup_recovery_points_size_bytes{region="us-east-1",resource="db-2",resource_type="instance"} 0
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,47 @@
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+// ttlCache keeps values fetched from the AWS APIs for ttl, for data that
+// changes too rarely to be fetched on every scrape. Errors are not cached.
+type ttlCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+func newTTLCache(ttl time.Duration) *ttlCache {
+	return &ttlCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]cacheEntry{},
+	}
+}
+
+// get returns the cached value for key, calling fetch to refresh it when it
+// is missing or expired
+func (c *ttlCache) get(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok && c.now().Before(e.expires) {
+		return e.value, nil
+	}
+
+	v, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	c.entries[key] = cacheEntry{value: v, expires: c.now().Add(c.ttl)}
+	return v, nil
+}
@@ -0,0 +1,337 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const (
+	// maxMetricDataQueries is the maximum number of queries of a GetMetricData request
+	maxMetricDataQueries = 500
+
+	cloudWatchSubsystem        = "cloudwatch"
+	cloudWatchClusterSubsystem = "cloudwatch_cluster"
+
+	instanceDimension = "DBInstanceIdentifier"
+)
+
+// cloudWatchNamespaces are the CloudWatch namespaces of the engine families
+var cloudWatchNamespaces = map[string]string{
+	engineFamilyRDS:     "AWS/RDS",
+	engineFamilyDocDB:   "AWS/DocDB",
+	engineFamilyNeptune: "AWS/Neptune",
+}
+
+// CloudWatchClient is a wrapper for AWS cloudwatch client that implements helpers to get RDS metrics
+type CloudWatchClient struct {
+	client cloudwatchiface.CloudWatchAPI
+}
+
+// CloudWatchGatherer is the interface that implements the methods required to gather CloudWatch metrics
+type CloudWatchGatherer interface {
+	GetMetricData(queries []*types.MetricQuery, start, end time.Time) ([]*types.MetricResult, error)
+}
+
+// NewCloudWatchClient will return an initialized CloudWatchClient. The
+// endpoint overrides the regional CloudWatch endpoint if it is not empty.
+func NewCloudWatchClient(awsRegion, endpoint string) (*CloudWatchClient, error) {
+	config := &aws.Config{Region: aws.String(awsRegion)}
+	if endpoint != "" {
+		config.Endpoint = aws.String(endpoint)
+	}
+
+	// Create AWS session
+	s := session.New(config)
+	if s == nil {
+		return nil, fmt.Errorf("error creating aws session")
+	}
+
+	return &CloudWatchClient{
+		client: cloudwatch.New(s),
+	}, nil
+}
+
+// GetMetricData will get the datapoints of the queries between start and end
+// from the CloudWatch API, in as few GetMetricData requests as possible
+func (e *CloudWatchClient) GetMetricData(queries []*types.MetricQuery, start, end time.Time) ([]*types.MetricResult, error) {
+	rs := []*types.MetricResult{}
+
+	for i := 0; i < len(queries); i += maxMetricDataQueries {
+		j := i + maxMetricDataQueries
+		if j > len(queries) {
+			j = len(queries)
+		}
+
+		params := &cloudwatch.GetMetricDataInput{
+			StartTime: aws.Time(start),
+			EndTime:   aws.Time(end),
+			ScanBy:    aws.String(cloudwatch.ScanByTimestampDescending),
+		}
+		for _, q := range queries[i:j] {
+			params.MetricDataQueries = append(params.MetricDataQueries, &cloudwatch.MetricDataQuery{
+				Id: aws.String(q.ID),
+				MetricStat: &cloudwatch.MetricStat{
+					Metric: &cloudwatch.Metric{
+						Namespace:  aws.String(q.Namespace),
+						MetricName: aws.String(q.MetricName),
+						Dimensions: []*cloudwatch.Dimension{{
+							Name:  aws.String(q.DimensionName),
+							Value: aws.String(q.DimensionValue),
+						}},
+					},
+					Period: aws.Int64(int64(q.Period)),
+					Stat:   aws.String(q.Statistic),
+				},
+			})
+		}
+
+		// results of a query can be split across pages
+		byID := map[string]*types.MetricResult{}
+		err := e.client.GetMetricDataPages(params, func(page *cloudwatch.GetMetricDataOutput, lastPage bool) bool {
+			for _, d := range page.MetricDataResults {
+				id := aws.StringValue(d.Id)
+				r, ok := byID[id]
+				if !ok {
+					r = &types.MetricResult{ID: id}
+					byID[id] = r
+					rs = append(rs, r)
+				}
+				r.Timestamps = append(r.Timestamps, aws.TimeValueSlice(d.Timestamps)...)
+				r.Values = append(r.Values, aws.Float64ValueSlice(d.Values)...)
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rs, nil
+}
+
+// metricSnakeCase converts a CloudWatch metric name, like CPUUtilization, to
+// snake case, like cpu_utilization. A plural s after an acronym, like in
+// VolumeReadIOPs, stays with the acronym.
+func metricSnakeCase(name string) string {
+	rs := []rune(name)
+	var b strings.Builder
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := rs[i-1]
+			next := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			plural := i+1 < len(rs) && rs[i+1] == 's' && (i+2 == len(rs) || unicode.IsUpper(rs[i+2]))
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && next && !plural) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// invalidNameChars matches the runs of characters that are not valid in a metric name
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// statisticSnakeCase converts a CloudWatch statistic, like Average, p99.9 or
+// TM(10%:90%), to a valid metric name part, like average, p99_9 or tm_10_90
+func statisticSnakeCase(statistic string) string {
+	return strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(statistic), "_"), "_")
+}
+
+// cloudWatchDesc returns the description of a CloudWatch metric statistic,
+// e.g. aws_rds_cloudwatch_cpu_utilization_average
+func cloudWatchDesc(subsystem, metric, statistic string, labels []string) *prometheus.Desc {
+	return prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, metricSnakeCase(metric)+"_"+statisticSnakeCase(statistic)),
+		fmt.Sprintf("%s of the CloudWatch metric %s", statistic, metric),
+		labels,
+		nil,
+	)
+}
+
+// cloudWatchQuery is a query of a CloudWatch collector with what it was built for
+type cloudWatchQuery struct {
+	query    *types.MetricQuery
+	desc     *prometheus.Desc
+	resource string
+}
+
+// cloudWatchScraper holds what the instance and cluster CloudWatch collectors
+// have in common: the statistics to query, the window and the descriptions
+type cloudWatchScraper struct {
+	client     CloudWatchGatherer
+	region     string
+	metrics    []string
+	statistics []string
+	period     time.Duration
+	delay      time.Duration
+	descs      map[string]*prometheus.Desc
+	logger     log.Logger
+	now        func() time.Time
+}
+
+// newCloudWatchScraper returns a scraper for the metrics and statistics,
+// without duplicates. The period must be a multiple of a minute, the
+// resolution of the standard RDS metrics.
+func newCloudWatchScraper(client CloudWatchGatherer, awsRegion string, metrics, statistics []string, period, delay time.Duration, subsystem string, labels []string, logger log.Logger) (cloudWatchScraper, error) {
+	if period < time.Minute || period%time.Minute != 0 {
+		return cloudWatchScraper{}, fmt.Errorf("invalid CloudWatch period %v, must be a multiple of 1m", period)
+	}
+
+	metrics = uniqueStrings(metrics)
+	statistics = uniqueStrings(statistics)
+	descs := map[string]*prometheus.Desc{}
+	for _, m := range metrics {
+		for _, s := range statistics {
+			descs[m+"/"+s] = cloudWatchDesc(subsystem, m, s, labels)
+		}
+	}
+
+	return cloudWatchScraper{
+		client:     client,
+		region:     awsRegion,
+		metrics:    metrics,
+		statistics: statistics,
+		period:     period,
+		delay:      delay,
+		descs:      descs,
+		logger:     logger,
+		now:        time.Now,
+	}, nil
+}
+
+// uniqueStrings returns ss without the repeated values, in the same order
+func uniqueStrings(ss []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, s := range ss {
+		if !seen[s] {
+			seen[s] = true
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
+
+// describe sends the descriptions of all the configured metric statistics
+func (c *cloudWatchScraper) describe(ch chan<- *prometheus.Desc) {
+	for _, d := range c.descs {
+		ch <- d
+	}
+}
+
+// queries returns a query for every configured metric statistic of the resource
+func (c *cloudWatchScraper) queries(qs []*cloudWatchQuery, cwNamespace, dimension, resource string) []*cloudWatchQuery {
+	for _, m := range c.metrics {
+		for _, s := range c.statistics {
+			qs = append(qs, &cloudWatchQuery{
+				query: &types.MetricQuery{
+					ID:             fmt.Sprintf("q%d", len(qs)),
+					Namespace:      cwNamespace,
+					MetricName:     m,
+					DimensionName:  dimension,
+					DimensionValue: resource,
+					Statistic:      s,
+					Period:         c.period.Seconds(),
+				},
+				desc:     c.descs[m+"/"+s],
+				resource: resource,
+			})
+		}
+	}
+	return qs
+}
+
+// scrape runs the queries and sends the newest datapoint of every query.
+// The window ends delay before now, since CloudWatch publishes datapoints
+// with a lag, and is aligned to the period.
+func (c *cloudWatchScraper) scrape(ch chan<- prometheus.Metric, qs []*cloudWatchQuery) {
+	if len(qs) == 0 {
+		return
+	}
+
+	end := c.now().Add(-c.delay).Truncate(c.period)
+	start := end.Add(-c.period)
+
+	byID := map[string]*cloudWatchQuery{}
+	mqs := []*types.MetricQuery{}
+	for _, q := range qs {
+		byID[q.query.ID] = q
+		mqs = append(mqs, q.query)
+	}
+
+	rs, err := c.client.GetMetricData(mqs, start, end)
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting CloudWatch metric data", "err", err)
+		return
+	}
+
+	for _, r := range rs {
+		q, ok := byID[r.ID]
+		if !ok || len(r.Values) == 0 {
+			continue
+		}
+		newest := 0
+		for i := range r.Timestamps {
+			if r.Timestamps[i].After(r.Timestamps[newest]) {
+				newest = i
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(q.desc, prometheus.GaugeValue, r.Values[newest], c.region, q.resource)
+	}
+}
+
+// NewCloudWatchCollector returns a collector for the CloudWatch metrics of
+// the RDS instances. The metrics and statistics of all instances are
+// queried together, in as few GetMetricData requests as possible.
+func NewCloudWatchCollector(rdsClient RDSGatherer, client CloudWatchGatherer, awsRegion string, metrics, statistics []string, period, delay time.Duration, logger log.Logger) (*cloudWatchCollector, error) {
+	scraper, err := newCloudWatchScraper(client, awsRegion, metrics, statistics, period, delay, cloudWatchSubsystem, labels, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cloudWatchCollector{
+		rdsClient: rdsClient,
+		scraper:   scraper,
+	}, nil
+}
+
+type cloudWatchCollector struct {
+	rdsClient RDSGatherer
+	scraper   cloudWatchScraper
+}
+
+// Describe describes the metrics exported by the CloudWatch collector. It
+// implements prometheus.Collector.
+func (c *cloudWatchCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.scraper.describe(ch)
+}
+
+// Collect fetches the CloudWatch metrics of the RDS instances and delivers
+// them as Prometheus metrics. It implements prometheus.Collector
+func (c *cloudWatchCollector) Collect(ch chan<- prometheus.Metric) {
+	rs, err := c.rdsClient.GetRDSInstances()
+	if err != nil {
+		level.Error(c.scraper.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+
+	qs := []*cloudWatchQuery{}
+	for _, r := range rs {
+		qs = c.scraper.queries(qs, cloudWatchNamespaces[engineFamily(r.Engine)], instanceDimension, r.Identifier)
+	}
+	c.scraper.scrape(ch, qs)
+}
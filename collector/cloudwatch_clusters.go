@@ -0,0 +1,63 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const clusterDimension = "DBClusterIdentifier"
+
+// clusterLabels are the labels of the cluster level CloudWatch metrics
+var clusterLabels = []string{"region", "cluster"}
+
+// ClusterGatherer is the interface that implements the methods required to gather RDS clusters
+type ClusterGatherer interface {
+	GetRDSClusters() ([]*types.DBCluster, error)
+}
+
+// NewCloudWatchClustersCollector returns a collector for the CloudWatch
+// metrics of the clusters, like the Aurora VolumeBytesUsed, which are only
+// published on the DBClusterIdentifier dimension
+func NewCloudWatchClustersCollector(rdsClient ClusterGatherer, client CloudWatchGatherer, awsRegion string, metrics, statistics []string, period, delay time.Duration, logger log.Logger) (*cloudWatchClustersCollector, error) {
+	scraper, err := newCloudWatchScraper(client, awsRegion, metrics, statistics, period, delay, cloudWatchClusterSubsystem, clusterLabels, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cloudWatchClustersCollector{
+		rdsClient: rdsClient,
+		scraper:   scraper,
+	}, nil
+}
+
+type cloudWatchClustersCollector struct {
+	rdsClient ClusterGatherer
+	scraper   cloudWatchScraper
+}
+
+// Describe describes the metrics exported by the cluster CloudWatch
+// collector. It implements prometheus.Collector.
+func (c *cloudWatchClustersCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.scraper.describe(ch)
+}
+
+// Collect fetches the CloudWatch metrics of the clusters and delivers them
+// as Prometheus metrics. It implements prometheus.Collector
+func (c *cloudWatchClustersCollector) Collect(ch chan<- prometheus.Metric) {
+	cs, err := c.rdsClient.GetRDSClusters()
+	if err != nil {
+		level.Error(c.scraper.logger).Log("msg", "Error getting RDS clusters", "err", err)
+		return
+	}
+
+	qs := []*cloudWatchQuery{}
+	for _, cluster := range cs {
+		qs = c.scraper.queries(qs, cloudWatchNamespaces[engineFamily(cluster.Engine)], clusterDimension, cluster.Identifier)
+	}
+	c.scraper.scrape(ch, qs)
+}
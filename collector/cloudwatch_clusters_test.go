@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+func TestCloudWatchClustersCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBClustersPages(t, mockRDS, false,
+		types.DBCluster{Identifier: "aurora-1", Engine: "aurora-postgresql"},
+		types.DBCluster{Identifier: "docdb-1", Engine: "docdb"},
+	)
+
+	cw := &fakeCloudWatchGatherer{datapoints: map[string][]float64{
+		"aurora-1/VolumeBytesUsed/Average":         {5e10},
+		"aurora-1/AuroraReplicaLagMaximum/Average": {12},
+		"docdb-1/VolumeBytesUsed/Average":          {1e9},
+	}}
+
+	c, err := NewCloudWatchClustersCollector(&RDSClient{client: mockRDS}, cw, "us-east-1",
+		[]string{"VolumeBytesUsed", "AuroraReplicaLagMaximum"}, []string{"Average"},
+		time.Minute, 5*time.Minute, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("Shouldn't return an error, but it did: %v", err)
+	}
+
+	want := `
+# HELP aws_rds_cloudwatch_cluster_aurora_replica_lag_maximum_average Average of the CloudWatch metric AuroraReplicaLagMaximum
+# TYPE aws_rds_cloudwatch_cluster_aurora_replica_lag_maximum_average gauge
+aws_rds_cloudwatch_cluster_aurora_replica_lag_maximum_average{cluster="aurora-1",region="us-east-1"} 12
+# HELP aws_rds_cloudwatch_cluster_volume_bytes_used_average Average of the CloudWatch metric VolumeBytesUsed
+# TYPE aws_rds_cloudwatch_cluster_volume_bytes_used_average gauge
+aws_rds_cloudwatch_cluster_volume_bytes_used_average{cluster="aurora-1",region="us-east-1"} 5e+10
+aws_rds_cloudwatch_cluster_volume_bytes_used_average{cluster="docdb-1",region="us-east-1"} 1e+09
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,172 @@
+package collector
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+// fakeCloudWatchAPI answers every query with a single datapoint, the number of the query
+type fakeCloudWatchAPI struct {
+	cloudwatchiface.CloudWatchAPI
+	requests int
+}
+
+func (f *fakeCloudWatchAPI) GetMetricDataPages(input *cloudwatch.GetMetricDataInput, fn func(*cloudwatch.GetMetricDataOutput, bool) bool) error {
+	f.requests++
+	out := &cloudwatch.GetMetricDataOutput{}
+	for _, q := range input.MetricDataQueries {
+		var n float64
+		fmt.Sscanf(aws.StringValue(q.Id), "q%g", &n)
+		out.MetricDataResults = append(out.MetricDataResults, &cloudwatch.MetricDataResult{
+			Id:         q.Id,
+			Timestamps: []*time.Time{input.EndTime},
+			Values:     []*float64{aws.Float64(n)},
+		})
+	}
+	fn(out, true)
+	return nil
+}
+
+// fakeCloudWatchGatherer answers every query with the datapoints of its metric name
+type fakeCloudWatchGatherer struct {
+	datapoints map[string][]float64
+}
+
+func (f *fakeCloudWatchGatherer) GetMetricData(queries []*types.MetricQuery, start, end time.Time) ([]*types.MetricResult, error) {
+	rs := []*types.MetricResult{}
+	for _, q := range queries {
+		r := &types.MetricResult{ID: q.ID}
+		for i, v := range f.datapoints[q.DimensionValue+"/"+q.MetricName+"/"+q.Statistic] {
+			r.Timestamps = append(r.Timestamps, end.Add(-time.Duration(i)*time.Minute))
+			r.Values = append(r.Values, v)
+		}
+		rs = append(rs, r)
+	}
+	return rs, nil
+}
+
+func TestMetricSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"CPUUtilization":      "cpu_utilization",
+		"FreeStorageSpace":    "free_storage_space",
+		"ReadIOPS":            "read_iops",
+		"ReplicaLag":          "replica_lag",
+		"BurstBalance":        "burst_balance",
+		"DatabaseConnections": "database_connections",
+		"VolumeReadIOPs":      "volume_read_iops",
+		"VolumeBytesUsed":     "volume_bytes_used",
+	}
+
+	for name, want := range tests {
+		if got := metricSnakeCase(name); got != want {
+			t.Errorf("metricSnakeCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestStatisticSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"Average":     "average",
+		"SampleCount": "samplecount",
+		"p99":         "p99",
+		"p99.9":       "p99_9",
+		"tm99.5":      "tm99_5",
+		"TM(10%:90%)": "tm_10_90",
+	}
+
+	for statistic, want := range tests {
+		if got := statisticSnakeCase(statistic); got != want {
+			t.Errorf("statisticSnakeCase(%q) = %q, want %q", statistic, got, want)
+		}
+		c, err := NewCloudWatchCollector(nil, nil, "us-east-1", []string{"CPUUtilization"}, []string{statistic}, time.Minute, time.Minute, log.NewNopLogger())
+		if err != nil {
+			t.Fatalf("Shouldn't return an error, but it did: %v", err)
+		}
+		if err := prometheus.NewPedanticRegistry().Register(c); err != nil {
+			t.Errorf("Registering the statistic %q failed: %v", statistic, err)
+		}
+	}
+}
+
+func TestGetMetricDataBatches(t *testing.T) {
+	api := &fakeCloudWatchAPI{}
+	e := &CloudWatchClient{client: api}
+
+	qs := []*types.MetricQuery{}
+	for i := 0; i < maxMetricDataQueries+1; i++ {
+		qs = append(qs, &types.MetricQuery{ID: fmt.Sprintf("q%d", i), Namespace: "AWS/RDS", MetricName: "CPUUtilization", Statistic: "Average", Period: 60})
+	}
+
+	rs, err := e.GetMetricData(qs, time.Now().Add(-time.Minute), time.Now())
+	if err != nil {
+		t.Fatalf("Shouldn't return an error, but it did: %v", err)
+	}
+	if api.requests != 2 {
+		t.Errorf("Wanted %d GetMetricData requests, got %d", 2, api.requests)
+	}
+	if len(rs) != len(qs) {
+		t.Errorf("Wanted %d results, got %d", len(qs), len(rs))
+	}
+}
+
+func TestCloudWatchCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false,
+		types.DBInstance{Identifier: "db-1", Engine: "mysql"},
+		types.DBInstance{Identifier: "db-2", Engine: "postgres"},
+	)
+
+	cw := &fakeCloudWatchGatherer{datapoints: map[string][]float64{
+		"db-1/CPUUtilization/Average":   {42, 10},
+		"db-1/CPUUtilization/Maximum":   {90},
+		"db-1/FreeStorageSpace/Average": {1e9},
+		"db-2/CPUUtilization/Average":   {7},
+	}}
+
+	c, err := NewCloudWatchCollector(&RDSClient{client: mockRDS}, cw, "us-east-1",
+		[]string{"CPUUtilization", "FreeStorageSpace", "CPUUtilization"}, []string{"Average", "Maximum", "Average"},
+		time.Minute, 5*time.Minute, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("Shouldn't return an error, but it did: %v", err)
+	}
+
+	want := `
+# HELP aws_rds_cloudwatch_cpu_utilization_average Average of the CloudWatch metric CPUUtilization
+# TYPE aws_rds_cloudwatch_cpu_utilization_average gauge
+aws_rds_cloudwatch_cpu_utilization_average{instance="db-1",region="us-east-1"} 42
+aws_rds_cloudwatch_cpu_utilization_average{instance="db-2",region="us-east-1"} 7
+# HELP aws_rds_cloudwatch_cpu_utilization_maximum Maximum of the CloudWatch metric CPUUtilization
+# TYPE aws_rds_cloudwatch_cpu_utilization_maximum gauge
+aws_rds_cloudwatch_cpu_utilization_maximum{instance="db-1",region="us-east-1"} 90
+# HELP aws_rds_cloudwatch_free_storage_space_average Average of the CloudWatch metric FreeStorageSpace
+# TYPE aws_rds_cloudwatch_free_storage_space_average gauge
+aws_rds_cloudwatch_free_storage_space_average{instance="db-1",region="us-east-1"} 1e+09
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCloudWatchCollectorInvalidPeriod(t *testing.T) {
+	for _, period := range []time.Duration{0, 30 * time.Second, 90 * time.Second} {
+		if _, err := NewCloudWatchCollector(nil, nil, "us-east-1", []string{"CPUUtilization"}, []string{"Average"}, period, time.Minute, log.NewNopLogger()); err == nil {
+			t.Errorf("Should return an error for a period of %v, but it didn't", period)
+		}
+	}
+}
@@ -38,12 +38,20 @@ var (
 		labels,
 		nil,
 	)
+
+	instanceInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "instance", "info"),
+		"Engine, engine version and engine family (rds, docdb or neptune) of the instance",
+		[]string{"region", "instance", "engine", "engine_version", "engine_family"},
+		nil,
+	)
 )
 
 // RDSClient is a wrapper for AWS rds client that implements helpers to get RDS metrics
 type RDSClient struct {
 	client        rdsiface.RDSAPI
 	apiMaxResults int64
+	engines       []string
 }
 
 // RDSGatherer is the interface that implements the methods required to gather RDS data
@@ -51,8 +59,9 @@ type RDSGatherer interface {
 	GetRDSInstances() ([]*types.DBInstance, error)
 }
 
-// NewRDSClient will return an initialized RDSClient
-func NewRDSClient(awsRegion string) (*RDSClient, error) {
+// NewRDSClient will return an initialized RDSClient. If engines is not empty,
+// only the instances and clusters of those engines are returned.
+func NewRDSClient(awsRegion string, engines []string) (*RDSClient, error) {
 	// Create AWS session
 	s := session.New(&aws.Config{Region: aws.String(awsRegion)})
 	if s == nil {
@@ -62,13 +71,27 @@ func NewRDSClient(awsRegion string) (*RDSClient, error) {
 	return &RDSClient{
 		client:        rds.New(s),
 		apiMaxResults: 100,
+		engines:       engines,
 	}, nil
 }
 
+// engineFilters returns the API filters restricting the results to the configured engines
+func (e *RDSClient) engineFilters() []*rds.Filter {
+	if len(e.engines) == 0 {
+		return nil
+	}
+	return []*rds.Filter{{
+		Name:   aws.String(engineFilterName),
+		Values: aws.StringSlice(e.engines),
+	}}
+}
+
 // GetRDSInstances will get the instances from the RDS API
 func (e *RDSClient) GetRDSInstances() ([]*types.DBInstance, error) {
 	rs := []*types.DBInstance{}
-	params := &rds.DescribeDBInstancesInput{}
+	params := &rds.DescribeDBInstancesInput{
+		Filters: e.engineFilters(),
+	}
 
 	resp, err := e.client.DescribeDBInstances(params)
 	if err != nil {
@@ -82,12 +105,41 @@ func (e *RDSClient) GetRDSInstances() ([]*types.DBInstance, error) {
 			c = float64(*(rdsInstance.Iops))
 		}
 
+		pgs := []string{}
+		for _, pg := range rdsInstance.DBParameterGroups {
+			pgs = append(pgs, aws.StringValue(pg.DBParameterGroupName))
+		}
+
 		// multiply by 10^9, so that it returns bytes (prometheus standard)
-		var b = (float64(*(rdsInstance.AllocatedStorage))) * math.Pow(10, 9)
+		var b = float64(aws.Int64Value(rdsInstance.AllocatedStorage)) * math.Pow(10, 9)
 		db := &types.DBInstance{
-			Identifier:       aws.StringValue(rdsInstance.DBInstanceIdentifier),
-			AllocatedStorage: b,
-			Iops:             c,
+			Identifier:                  aws.StringValue(rdsInstance.DBInstanceIdentifier),
+			AllocatedStorage:            b,
+			Iops:                        c,
+			Class:                       aws.StringValue(rdsInstance.DBInstanceClass),
+			Engine:                      aws.StringValue(rdsInstance.Engine),
+			EngineVersion:               aws.StringValue(rdsInstance.EngineVersion),
+			ParameterGroups:             pgs,
+			MultiAZ:                     aws.BoolValue(rdsInstance.MultiAZ),
+			StorageType:                 aws.StringValue(rdsInstance.StorageType),
+			LicenseModel:                aws.StringValue(rdsInstance.LicenseModel),
+			ReplicaSource:               aws.StringValue(rdsInstance.ReadReplicaSourceDBInstanceIdentifier),
+			ResourceID:                  aws.StringValue(rdsInstance.DbiResourceId),
+			MonitoringInterval:          float64(aws.Int64Value(rdsInstance.MonitoringInterval)),
+			PerformanceInsights:         aws.BoolValue(rdsInstance.PerformanceInsightsEnabled),
+			Arn:                         aws.StringValue(rdsInstance.DBInstanceArn),
+			Cluster:                     aws.StringValue(rdsInstance.DBClusterIdentifier),
+			KmsKeyID:                    aws.StringValue(rdsInstance.KmsKeyId),
+			PerformanceInsightsKmsKeyID: aws.StringValue(rdsInstance.PerformanceInsightsKMSKeyId),
+		}
+		if len(rdsInstance.TagList) > 0 {
+			db.Tags = map[string]string{}
+			for _, tag := range rdsInstance.TagList {
+				db.Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+			}
+		}
+		if rdsInstance.DBSubnetGroup != nil {
+			db.SubnetGroup = aws.StringValue(rdsInstance.DBSubnetGroup.DBSubnetGroupName)
 		}
 
 		rs = append(rs, db)
@@ -96,9 +148,35 @@ func (e *RDSClient) GetRDSInstances() ([]*types.DBInstance, error) {
 	return rs, nil
 }
 
-func NewExporter(awsRegion string) (*exporter, error) {
+// GetRDSClusters will get the clusters from the RDS API
+func (e *RDSClient) GetRDSClusters() ([]*types.DBCluster, error) {
+	cs := []*types.DBCluster{}
+	params := &rds.DescribeDBClustersInput{
+		Filters:    e.engineFilters(),
+		MaxRecords: aws.Int64(e.apiMaxResults),
+	}
 
-	RdsClient, err := NewRDSClient(awsRegion)
+	err := e.client.DescribeDBClustersPages(params, func(page *rds.DescribeDBClustersOutput, lastPage bool) bool {
+		for _, rdsCluster := range page.DBClusters {
+			cs = append(cs, &types.DBCluster{
+				Identifier: aws.StringValue(rdsCluster.DBClusterIdentifier),
+				Engine:     aws.StringValue(rdsCluster.Engine),
+				Arn:        aws.StringValue(rdsCluster.DBClusterArn),
+				KmsKeyID:   aws.StringValue(rdsCluster.KmsKeyId),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cs, nil
+}
+
+func NewExporter(awsRegion string, engines []string) (*exporter, error) {
+
+	RdsClient, err := NewRDSClient(awsRegion, engines)
 
 	if err != nil {
 		fmt.Printf("Error with rds client")
@@ -121,6 +199,7 @@ type exporter struct {
 func (e *exporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- storage
 	ch <- iops
+	ch <- instanceInfo
 }
 
 // Collect fetches the stats from the configured RDS and delivers them
@@ -134,6 +213,13 @@ func (e *exporter) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	for _, r := range rs {
+		ch <- prometheus.MustNewConstMetric(
+			instanceInfo, prometheus.GaugeValue, 1, e.region, r.Identifier, r.Engine, r.EngineVersion, engineFamily(r.Engine),
+		)
+		// DocumentDB and Neptune report a placeholder AllocatedStorage
+		if !hasInstanceStorage(r.Engine) {
+			continue
+		}
 		ch <- prometheus.MustNewConstMetric(
 			storage, prometheus.GaugeValue, r.AllocatedStorage, e.region, r.Identifier,
 		)
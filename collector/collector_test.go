@@ -2,8 +2,11 @@ package collector
 
 import (
 	"math"
+	"strings"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
 	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
 	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
 	"github.com/alecrajeev/aws_rds_exporter/types"
@@ -69,3 +72,54 @@ func TestGetRDSInstances(t *testing.T) {
 		}
 	}
 }
+
+func TestGetRDSInstancesEngineFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false,
+		types.DBInstance{Identifier: "rds-1", Engine: "mysql", AllocatedStorage: 20},
+		types.DBInstance{Identifier: "docdb-1", Engine: "docdb"},
+		types.DBInstance{Identifier: "neptune-1", Engine: "neptune"},
+	)
+
+	e := &RDSClient{client: mockRDS, engines: []string{"docdb", "neptune"}}
+
+	rdsInstances, err := e.GetRDSInstances()
+	if err != nil {
+		t.Fatalf("Shouldn't return an error, but it did: %v", err)
+	}
+	if len(rdsInstances) != 2 {
+		t.Fatalf("Length in returned number of RDS Instances differs than expected, want: %d; got: %d", 2, len(rdsInstances))
+	}
+	for _, got := range rdsInstances {
+		if got.AllocatedStorage != 0 {
+			t.Errorf("Wanted no AllocatedStorage for %v, got %v", got.Identifier, got.AllocatedStorage)
+		}
+	}
+}
+
+func TestExporterEngineFamilies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false,
+		types.DBInstance{Identifier: "rds-1", Engine: "mysql", EngineVersion: "8.0.21", AllocatedStorage: 20},
+		types.DBInstance{Identifier: "docdb-1", Engine: "docdb", EngineVersion: "3.6.0"},
+	)
+
+	e := &exporter{client: &RDSClient{client: mockRDS}, region: "us-east-1"}
+
+	want := `
+# HELP aws_rds_instance_info Engine, engine version and engine family (rds, docdb or neptune) of the instance
+# TYPE aws_rds_instance_info gauge
+aws_rds_instance_info{engine="docdb",engine_family="docdb",engine_version="3.6.0",instance="docdb-1",region="us-east-1"} 1
+aws_rds_instance_info{engine="mysql",engine_family="rds",engine_version="8.0.21",instance="rds-1",region="us-east-1"} 1
+# HELP aws_rds_storage Amount of storage in bytes for the RDS instance
+# TYPE aws_rds_storage gauge
+aws_rds_storage{instance="rds-1",region="us-east-1"} 2e+10
+`
+	if err := testutil.CollectAndCompare(e, strings.NewReader(want), "aws_rds_instance_info", "aws_rds_storage"); err != nil {
+		t.Error(err)
+	}
+}
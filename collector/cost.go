@@ -0,0 +1,181 @@
+package collector
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics descriptions
+var (
+	instanceEstimatedCost = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "instance", "estimated_cost_dollars_per_hour"),
+		"Estimated on-demand cost of the RDS instance and its storage in dollars per hour",
+		labels,
+		nil,
+	)
+
+	instanceEstimatedCostComponent = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "instance", "estimated_cost_component_dollars_per_hour"),
+		"Estimated on-demand cost of the RDS instance in dollars per hour by component (compute, storage or iops)",
+		[]string{"region", "instance", "component"},
+		nil,
+	)
+
+	instanceTagsName = prometheus.BuildFQName(namespace, "instance", "tags_info")
+	instanceTagsHelp = "Tags of the RDS instance as tag_<key> labels, to aggregate the estimated cost by"
+
+	instanceCostEstimated = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "instance", "cost_estimated"),
+		"Whether the price list has a price for the class, engine, license model and deployment of the RDS instance",
+		labels,
+		nil,
+	)
+)
+
+// NewCostCollector returns a collector that estimates the cost of the RDS
+// instances from the price list at priceListPath, see loadPriceList
+func NewCostCollector(client RDSGatherer, awsRegion, priceListPath string, logger log.Logger) (*costCollector, error) {
+	prices, err := loadPriceList(priceListPath, awsRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &costCollector{
+		client: client,
+		region: awsRegion,
+		prices: prices,
+		logger: logger,
+	}, nil
+}
+
+type costCollector struct {
+	client RDSGatherer
+	region string
+	prices *priceList
+	logger log.Logger
+}
+
+// Describe describes the metrics exported by the cost collector. It
+// implements prometheus.Collector.
+func (c *costCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- instanceEstimatedCost
+	ch <- instanceEstimatedCostComponent
+	ch <- instanceCostEstimated
+}
+
+// Collect estimates the cost of every RDS instance and delivers it as
+// Prometheus metrics. It implements prometheus.Collector
+func (c *costCollector) Collect(ch chan<- prometheus.Metric) {
+	rs, err := c.client.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+
+	for _, r := range rs {
+		compute, ok := c.prices.instancePrice(r.Class, r.Engine, r.LicenseModel, r.MultiAZ)
+		ch <- prometheus.MustNewConstMetric(instanceCostEstimated, prometheus.GaugeValue, boolToFloat(ok), c.region, r.Identifier)
+		if !ok {
+			level.Debug(c.logger).Log("msg", "No price for RDS instance", "instance", r.Identifier, "class", r.Class, "engine", r.Engine)
+			continue
+		}
+		total := compute
+		ch <- prometheus.MustNewConstMetric(instanceEstimatedCostComponent, prometheus.GaugeValue, compute, c.region, r.Identifier, "compute")
+
+		// Aurora, DocumentDB and Neptune storage is billed on the cluster volume
+		if hasInstanceStorage(r.Engine) && !strings.HasPrefix(r.Engine, "aurora") {
+			if price, ok := c.prices.storagePrice(r.StorageType, r.MultiAZ); ok {
+				// AllocatedStorage is in bytes, prices are per GB-month
+				storageCost := r.AllocatedStorage / math.Pow(10, 9) * price / hoursPerMonth
+				total += storageCost
+				ch <- prometheus.MustNewConstMetric(instanceEstimatedCostComponent, prometheus.GaugeValue, storageCost, c.region, r.Identifier, "storage")
+			}
+			if price, ok := c.prices.iopsPrice(r.StorageType, r.MultiAZ); ok && r.Iops > 0 {
+				iopsCost := r.Iops * price / hoursPerMonth
+				total += iopsCost
+				ch <- prometheus.MustNewConstMetric(instanceEstimatedCostComponent, prometheus.GaugeValue, iopsCost, c.region, r.Identifier, "iops")
+			}
+		}
+
+		ch <- prometheus.MustNewConstMetric(instanceEstimatedCost, prometheus.GaugeValue, total, c.region, r.Identifier)
+	}
+}
+
+// invalidLabelChars matches the characters of a tag key that are not valid in a label name
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// tagLabel returns the label name of a tag key
+func tagLabel(key string) string {
+	return "tag_" + strings.ToLower(invalidLabelChars.ReplaceAllString(key, "_"))
+}
+
+// NewInstanceTagsCollector returns a collector for the tags of the RDS instances
+func NewInstanceTagsCollector(client RDSGatherer, awsRegion string, logger log.Logger) *instanceTagsCollector {
+	return &instanceTagsCollector{
+		client: client,
+		region: awsRegion,
+		logger: logger,
+	}
+}
+
+type instanceTagsCollector struct {
+	client RDSGatherer
+	region string
+	logger log.Logger
+}
+
+// Describe describes nothing, the labels of the tags metric depend on the
+// tags found on every scrape, which makes it an unchecked collector. It
+// implements prometheus.Collector.
+func (c *instanceTagsCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+// Collect fetches the tags of every RDS instance and delivers them as one
+// Prometheus metric per instance, with a label for every tag key found on
+// any instance. It implements prometheus.Collector
+func (c *instanceTagsCollector) Collect(ch chan<- prometheus.Metric) {
+	rs, err := c.client.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+
+	// tag keys that map to the same label are merged, the first key in
+	// sorted order wins
+	keys := map[string]bool{}
+	for _, r := range rs {
+		for k := range r.Tags {
+			keys[k] = true
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	labelKeys := map[string]string{}
+	tagLabels := []string{}
+	for _, k := range sorted {
+		l := tagLabel(k)
+		if _, ok := labelKeys[l]; ok {
+			continue
+		}
+		labelKeys[l] = k
+		tagLabels = append(tagLabels, l)
+	}
+
+	desc := prometheus.NewDesc(instanceTagsName, instanceTagsHelp, append([]string{"region", "instance"}, tagLabels...), nil)
+	for _, r := range rs {
+		values := []string{c.region, r.Identifier}
+		for _, l := range tagLabels {
+			values = append(values, r.Tags[labelKeys[l]])
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, values...)
+	}
+}
@@ -0,0 +1,162 @@
+package collector
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const testPriceListCSV = `kind,region,class,engine,license_model,deployment,storage_type,price
+# compute in dollars per hour, storage per GB-month, iops per IOPS-month
+instance,us-east-1,db.m5.large,mysql,,single-az,,0.171
+instance,us-east-1,db.m5.large,mysql,,multi-az,,0.342
+instance,us-west-2,db.m5.large,mysql,,single-az,,0.5
+instance,us-east-1,db.r5.large,aurora-postgresql,,single-az,,0.29
+storage,us-east-1,,,,single-az,gp2,0.115
+storage,us-east-1,,,,multi-az,io1,0.25
+iops,us-east-1,,,,multi-az,io1,0.2
+`
+
+const testPriceListOffer = `{
+	"products": {
+		"SKU1": {"sku": "SKU1", "productFamily": "Database Instance", "attributes": {
+			"location": "US East (N. Virginia)", "instanceType": "db.m5.large", "databaseEngine": "Oracle",
+			"databaseEdition": "Standard Two", "licenseModel": "License included", "deploymentOption": "Single-AZ"}},
+		"SKU2": {"sku": "SKU2", "productFamily": "Database Storage", "attributes": {
+			"regionCode": "us-east-1", "volumeType": "General Purpose", "deploymentOption": "Single-AZ"}},
+		"SKU3": {"sku": "SKU3", "productFamily": "Database Instance", "attributes": {
+			"regionCode": "eu-west-1", "instanceType": "db.m5.large", "databaseEngine": "MySQL",
+			"licenseModel": "No license required", "deploymentOption": "Single-AZ"}},
+		"SKU4": {"sku": "SKU4", "productFamily": "Database Instance", "attributes": {
+			"regionCode": "us-east-1", "instanceType": "db.m5.xlarge", "databaseEngine": "SQL Server",
+			"databaseEdition": "Standard", "licenseModel": "License included", "deploymentOption": "Multi-AZ (SQL Server Mirror)"}},
+		"SKU5": {"sku": "SKU5", "productFamily": "Database Storage", "attributes": {
+			"regionCode": "us-east-1", "volumeType": "General Purpose", "databaseEngine": "SQL Server", "deploymentOption": "Single-AZ"}}
+	},
+	"terms": {
+		"OnDemand": {
+			"SKU1": {"SKU1.JRTCKXETXF": {"priceDimensions": {
+				"SKU1.JRTCKXETXF.6YS6EN2CT7": {"unit": "Hrs", "pricePerUnit": {"USD": "0.408"}},
+				"SKU1.JRTCKXETXF.2TG2D8R56U": {"unit": "Hrs", "pricePerUnit": {"USD": "0.0000000000"}}}}},
+			"SKU2": {"SKU2.JRTCKXETXF": {"priceDimensions": {
+				"SKU2.JRTCKXETXF.6YS6EN2CT7": {"unit": "GB-Mo", "beginRange": "0", "endRange": "1000", "pricePerUnit": {"USD": "0.115"}},
+				"SKU2.JRTCKXETXF.PGHJ3S3EYE": {"unit": "GB-Mo", "beginRange": "1000", "endRange": "Inf", "pricePerUnit": {"USD": "0.05"}}}}},
+			"SKU3": {"SKU3.JRTCKXETXF": {"priceDimensions": {"SKU3.JRTCKXETXF.6YS6EN2CT7": {"unit": "Hrs", "pricePerUnit": {"USD": "0.19"}}}}},
+			"SKU4": {"SKU4.JRTCKXETXF": {"priceDimensions": {"SKU4.JRTCKXETXF.6YS6EN2CT7": {"unit": "Hrs", "pricePerUnit": {"USD": "2.954"}}}}},
+			"SKU5": {"SKU5.JRTCKXETXF": {"priceDimensions": {"SKU5.JRTCKXETXF.6YS6EN2CT7": {"unit": "GB-Mo", "pricePerUnit": {"USD": "0.08"}}}}}
+		}
+	}
+}`
+
+func writePriceList(t *testing.T, name, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "prices")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestLoadPriceListOffer(t *testing.T) {
+	path, cleanup := writePriceList(t, "AmazonRDS.json", testPriceListOffer)
+	defer cleanup()
+
+	p, err := loadPriceList(path, "us-east-1")
+	if err != nil {
+		t.Fatalf("Shouldn't return an error, but it did: %v", err)
+	}
+	if v, ok := p.instancePrice("db.m5.large", "oracle-se2", "license-included", false); !ok || v != 0.408 {
+		t.Errorf("Wanted an oracle-se2 price of 0.408, got %v, %v", v, ok)
+	}
+	if v, ok := p.storagePrice("gp2", false); !ok || v != 0.115 {
+		t.Errorf("Wanted a gp2 price of 0.115, got %v, %v", v, ok)
+	}
+	if v, ok := p.instancePrice("db.m5.xlarge", "sqlserver-se", "license-included", true); !ok || v != 2.954 {
+		t.Errorf("Wanted a Multi-AZ sqlserver-se price of 2.954, got %v, %v", v, ok)
+	}
+	if _, ok := p.instancePrice("db.m5.large", "mysql", "general-public-license", false); ok {
+		t.Errorf("Wanted no price for the eu-west-1 product")
+	}
+}
+
+func TestCostCollector(t *testing.T) {
+	path, cleanup := writePriceList(t, "prices.csv", testPriceListCSV)
+	defer cleanup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false,
+		types.DBInstance{Identifier: "db-1", Class: "db.m5.large", Engine: "mysql", LicenseModel: "general-public-license", StorageType: "gp2", AllocatedStorage: 100},
+		types.DBInstance{Identifier: "db-2", Class: "db.m5.large", Engine: "mysql", LicenseModel: "general-public-license", StorageType: "io1", AllocatedStorage: 73, Iops: 1000, MultiAZ: true},
+		types.DBInstance{Identifier: "db-3", Class: "db.r5.large", Engine: "aurora-postgresql", LicenseModel: "postgresql-license", StorageType: "aurora", AllocatedStorage: 1},
+		types.DBInstance{Identifier: "db-4", Class: "db.x1.huge", Engine: "mysql", StorageType: "gp2", AllocatedStorage: 20},
+	)
+
+	c, err := NewCostCollector(&RDSClient{client: mockRDS}, "us-east-1", path, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("Shouldn't return an error, but it did: %v", err)
+	}
+
+	want := `
+# HELP aws_rds_instance_cost_estimated Whether the price list has a price for the class, engine, license model and deployment of the RDS instance
+# TYPE aws_rds_instance_cost_estimated gauge
+aws_rds_instance_cost_estimated{instance="db-1",region="us-east-1"} 1
+aws_rds_instance_cost_estimated{instance="db-2",region="us-east-1"} 1
+aws_rds_instance_cost_estimated{instance="db-3",region="us-east-1"} 1
+aws_rds_instance_cost_estimated{instance="db-4",region="us-east-1"} 0
+# HELP aws_rds_instance_estimated_cost_component_dollars_per_hour Estimated on-demand cost of the RDS instance in dollars per hour by component (compute, storage or iops)
+# TYPE aws_rds_instance_estimated_cost_component_dollars_per_hour gauge
+aws_rds_instance_estimated_cost_component_dollars_per_hour{component="compute",instance="db-1",region="us-east-1"} 0.171
+aws_rds_instance_estimated_cost_component_dollars_per_hour{component="compute",instance="db-2",region="us-east-1"} 0.342
+aws_rds_instance_estimated_cost_component_dollars_per_hour{component="compute",instance="db-3",region="us-east-1"} 0.29
+aws_rds_instance_estimated_cost_component_dollars_per_hour{component="iops",instance="db-2",region="us-east-1"} 0.273972602739726
+aws_rds_instance_estimated_cost_component_dollars_per_hour{component="storage",instance="db-1",region="us-east-1"} 0.015753424657534248
+aws_rds_instance_estimated_cost_component_dollars_per_hour{component="storage",instance="db-2",region="us-east-1"} 0.025
+# HELP aws_rds_instance_estimated_cost_dollars_per_hour Estimated on-demand cost of the RDS instance and its storage in dollars per hour
+# TYPE aws_rds_instance_estimated_cost_dollars_per_hour gauge
+aws_rds_instance_estimated_cost_dollars_per_hour{instance="db-1",region="us-east-1"} 0.18675342465753425
+aws_rds_instance_estimated_cost_dollars_per_hour{instance="db-2",region="us-east-1"} 0.640972602739726
+aws_rds_instance_estimated_cost_dollars_per_hour{instance="db-3",region="us-east-1"} 0.29
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInstanceTagsCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false,
+		types.DBInstance{Identifier: "db-1", Tags: map[string]string{"team": "payments", "cost-center": "42"}},
+		types.DBInstance{Identifier: "db-2", Tags: map[string]string{"team": "search"}},
+		types.DBInstance{Identifier: "db-3"},
+	)
+
+	c := NewInstanceTagsCollector(&RDSClient{client: mockRDS}, "us-east-1", log.NewNopLogger())
+
+	want := `
+# HELP aws_rds_instance_tags_info Tags of the RDS instance as tag_<key> labels, to aggregate the estimated cost by
+# TYPE aws_rds_instance_tags_info gauge
+aws_rds_instance_tags_info{instance="db-1",region="us-east-1",tag_cost_center="42",tag_team="payments"} 1
+aws_rds_instance_tags_info{instance="db-2",region="us-east-1",tag_cost_center="",tag_team="search"} 1
+aws_rds_instance_tags_info{instance="db-3",region="us-east-1",tag_cost_center="",tag_team=""} 1
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,31 @@
+package collector
+
+import "strings"
+
+// Engine families served by the RDS API. DocumentDB and Neptune clusters are
+// managed through the same API calls, but keep their data in the cluster
+// volume, so instance level fields like AllocatedStorage don't apply to them.
+const (
+	engineFamilyRDS     = "rds"
+	engineFamilyDocDB   = "docdb"
+	engineFamilyNeptune = "neptune"
+
+	engineFilterName = "engine"
+)
+
+// engineFamily returns the service the engine belongs to
+func engineFamily(engine string) string {
+	switch {
+	case strings.HasPrefix(engine, engineFamilyDocDB):
+		return engineFamilyDocDB
+	case strings.HasPrefix(engine, engineFamilyNeptune):
+		return engineFamilyNeptune
+	default:
+		return engineFamilyRDS
+	}
+}
+
+// hasInstanceStorage returns whether the instances of the engine have their own storage
+func hasInstanceStorage(engine string) bool {
+	return engineFamily(engine) == engineFamilyRDS
+}
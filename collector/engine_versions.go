@@ -0,0 +1,227 @@
+package collector
+
+import (
+	"strconv"
+	"time"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const engineVersionAvailable = "available"
+
+// Metrics descriptions
+var (
+	engineVersionLabels = []string{"region", "instance", "engine", "engine_version"}
+
+	engineVersionOffered = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "engine_version", "offered"),
+		"Whether the engine version of the RDS instance is still offered by RDS",
+		engineVersionLabels,
+		nil,
+	)
+
+	engineVersionMinorBehind = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "engine_version", "minor_versions_behind"),
+		"Number of offered versions in the major line of the RDS instance that are newer than its engine version",
+		engineVersionLabels,
+		nil,
+	)
+
+	engineVersionUpgradeTargets = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "engine_version", "upgrade_targets"),
+		"Number of valid upgrade targets for the engine version of the RDS instance",
+		append(engineVersionLabels, "upgrade"),
+		nil,
+	)
+)
+
+// EngineVersionGatherer is the interface that implements the methods required to gather RDS engine version data
+type EngineVersionGatherer interface {
+	RDSGatherer
+	GetRDSEngineVersions(engine string) ([]*types.DBEngineVersion, error)
+}
+
+// GetRDSEngineVersions will get the available and deprecated versions of the engine from the RDS API
+func (e *RDSClient) GetRDSEngineVersions(engine string) ([]*types.DBEngineVersion, error) {
+	vs := []*types.DBEngineVersion{}
+	params := &rds.DescribeDBEngineVersionsInput{
+		Engine:     aws.String(engine),
+		IncludeAll: aws.Bool(true),
+		MaxRecords: aws.Int64(e.apiMaxResults),
+	}
+
+	err := e.client.DescribeDBEngineVersionsPages(params, func(page *rds.DescribeDBEngineVersionsOutput, lastPage bool) bool {
+		for _, v := range page.DBEngineVersions {
+			version := &types.DBEngineVersion{
+				Engine:  aws.StringValue(v.Engine),
+				Version: aws.StringValue(v.EngineVersion),
+				Family:  aws.StringValue(v.DBParameterGroupFamily),
+				Status:  aws.StringValue(v.Status),
+			}
+			for _, t := range v.ValidUpgradeTarget {
+				version.UpgradeTargets = append(version.UpgradeTargets, types.UpgradeTarget{
+					Version:               aws.StringValue(t.EngineVersion),
+					IsMajorVersionUpgrade: aws.BoolValue(t.IsMajorVersionUpgrade),
+				})
+			}
+			vs = append(vs, version)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return vs, nil
+}
+
+// compareVersions compares two engine versions, returning -1, 0 or 1.
+// Versions are compared token by token, numeric tokens numerically, so
+// 5.7.10 is newer than 5.7.9 and 5.7.mysql_aurora.2.10.0 newer than
+// 5.7.mysql_aurora.2.09.2.
+func compareVersions(a, b string) int {
+	ta, tb := versionTokens(a), versionTokens(b)
+	for i := 0; i < len(ta) && i < len(tb); i++ {
+		na, errA := strconv.Atoi(ta[i])
+		nb, errB := strconv.Atoi(tb[i])
+		switch {
+		case errA == nil && errB == nil && na != nb:
+			if na < nb {
+				return -1
+			}
+			return 1
+		case (errA != nil || errB != nil) && ta[i] != tb[i]:
+			if ta[i] < tb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(ta) < len(tb):
+		return -1
+	case len(ta) > len(tb):
+		return 1
+	}
+	return 0
+}
+
+// versionTokens splits a version into runs of digits and runs of letters
+func versionTokens(v string) []string {
+	tokens := []string{}
+	current := []rune{}
+	for _, r := range v {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(current) > 0 {
+				tokens = append(tokens, string(current))
+				current = current[:0]
+			}
+			continue
+		}
+		if len(current) > 0 && unicode.IsDigit(r) != unicode.IsDigit(current[0]) {
+			tokens = append(tokens, string(current))
+			current = current[:0]
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		tokens = append(tokens, string(current))
+	}
+	return tokens
+}
+
+// NewEngineVersionsCollector returns a collector that checks the engine
+// versions of the RDS instances against the RDS catalog. The catalog is
+// cached for cacheTTL.
+func NewEngineVersionsCollector(client EngineVersionGatherer, awsRegion string, cacheTTL time.Duration, logger log.Logger) *engineVersionsCollector {
+	return &engineVersionsCollector{
+		client: client,
+		region: awsRegion,
+		cache:  newTTLCache(cacheTTL),
+		logger: logger,
+	}
+}
+
+type engineVersionsCollector struct {
+	client EngineVersionGatherer
+	region string
+	cache  *ttlCache
+	logger log.Logger
+}
+
+// Describe describes the metrics exported by the engine versions collector.
+// It implements prometheus.Collector.
+func (c *engineVersionsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- engineVersionOffered
+	ch <- engineVersionMinorBehind
+	ch <- engineVersionUpgradeTargets
+}
+
+// Collect checks every RDS instance against the engine version catalog and
+// delivers the results as Prometheus metrics. It implements prometheus.Collector
+func (c *engineVersionsCollector) Collect(ch chan<- prometheus.Metric) {
+	rs, err := c.client.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+
+	for _, r := range rs {
+		v, err := c.cache.get(r.Engine, func() (interface{}, error) {
+			return c.client.GetRDSEngineVersions(r.Engine)
+		})
+		if err != nil {
+			level.Error(c.logger).Log("msg", "Error getting RDS engine versions", "engine", r.Engine, "err", err)
+			continue
+		}
+		catalog := v.([]*types.DBEngineVersion)
+
+		var current *types.DBEngineVersion
+		for _, version := range catalog {
+			if version.Version == r.EngineVersion {
+				current = version
+				break
+			}
+		}
+
+		offered := current != nil && current.Status == engineVersionAvailable
+		ch <- prometheus.MustNewConstMetric(
+			engineVersionOffered, prometheus.GaugeValue, boolToFloat(offered), c.region, r.Identifier, r.Engine, r.EngineVersion,
+		)
+		if current == nil {
+			continue
+		}
+
+		behind := 0
+		for _, version := range catalog {
+			if version.Family == current.Family && version.Status == engineVersionAvailable && compareVersions(version.Version, current.Version) > 0 {
+				behind++
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(
+			engineVersionMinorBehind, prometheus.GaugeValue, float64(behind), c.region, r.Identifier, r.Engine, r.EngineVersion,
+		)
+
+		major, minor := 0, 0
+		for _, t := range current.UpgradeTargets {
+			if t.IsMajorVersionUpgrade {
+				major++
+			} else {
+				minor++
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(
+			engineVersionUpgradeTargets, prometheus.GaugeValue, float64(major), c.region, r.Identifier, r.Engine, r.EngineVersion, "major",
+		)
+		ch <- prometheus.MustNewConstMetric(
+			engineVersionUpgradeTargets, prometheus.GaugeValue, float64(minor), c.region, r.Identifier, r.Engine, r.EngineVersion, "minor",
+		)
+	}
+}
@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"5.7.9", "5.7.10", -1},
+		{"12.5", "12.5", 0},
+		{"9.6.20", "10.1", -1},
+		{"5.7.mysql_aurora.2.10.0", "5.7.mysql_aurora.2.09.2", 1},
+		{"19.0.0.0.ru-2021-01.rur-2021-01.r1", "19.0.0.0.ru-2020-10.rur-2020-10.r1", 1},
+		{"8.0.21", "8.0", 1},
+	}
+
+	for _, test := range tests {
+		if got := compareVersions(test.a, test.b); got != test.want {
+			t.Errorf("compareVersions(%q, %q): wanted %d, got %d", test.a, test.b, test.want, got)
+		}
+	}
+}
+
+func TestEngineVersionsCollector(t *testing.T) {
+	instance := types.DBInstance{Identifier: "rds-dbinstance-1", AllocatedStorage: 55.0, Engine: "postgres", EngineVersion: "12.4"}
+	catalog := []types.DBEngineVersion{
+		{Engine: "postgres", Version: "11.9", Family: "postgres11", Status: "available"},
+		{Engine: "postgres", Version: "12.4", Family: "postgres12", Status: "available", UpgradeTargets: []types.UpgradeTarget{
+			{Version: "12.5"}, {Version: "12.6"}, {Version: "13.1", IsMajorVersionUpgrade: true},
+		}},
+		{Engine: "postgres", Version: "12.5", Family: "postgres12", Status: "available"},
+		{Engine: "postgres", Version: "12.6", Family: "postgres12", Status: "available"},
+		{Engine: "postgres", Version: "13.1", Family: "postgres13", Status: "available"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false, instance)
+	awsMock.MockDescribeDBEngineVersionsPages(t, mockRDS, false, catalog...)
+
+	c := NewEngineVersionsCollector(&RDSClient{client: mockRDS}, "us-east-1", time.Hour, log.NewNopLogger())
+
+	want := `
+# HELP aws_rds_engine_version_minor_versions_behind Number of offered versions in the major line of the RDS instance that are newer than its engine version
+# TYPE aws_rds_engine_version_minor_versions_behind gauge
+aws_rds_engine_version_minor_versions_behind{engine="postgres",engine_version="12.4",instance="rds-dbinstance-1",region="us-east-1"} 2
+# HELP aws_rds_engine_version_offered Whether the engine version of the RDS instance is still offered by RDS
+# TYPE aws_rds_engine_version_offered gauge
+aws_rds_engine_version_offered{engine="postgres",engine_version="12.4",instance="rds-dbinstance-1",region="us-east-1"} 1
+# HELP aws_rds_engine_version_upgrade_targets Number of valid upgrade targets for the engine version of the RDS instance
+# TYPE aws_rds_engine_version_upgrade_targets gauge
+aws_rds_engine_version_upgrade_targets{engine="postgres",engine_version="12.4",instance="rds-dbinstance-1",region="us-east-1",upgrade="major"} 1
+aws_rds_engine_version_upgrade_targets{engine="postgres",engine_version="12.4",instance="rds-dbinstance-1",region="us-east-1",upgrade="minor"} 2
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,573 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// enhancedMonitoringLogGroup is the log group Enhanced Monitoring
+	// publishes to, with one log stream per DbiResourceId
+	enhancedMonitoringLogGroup = "RDSOSMetrics"
+
+	osSubsystem = "os"
+
+	// kibibyte is the unit of the memory, swap and file system sizes of the payload
+	kibibyte = 1024
+
+	// processMetricsTop is the number of processes exported as metrics per
+	// instance, to bound the cardinality of the process metrics
+	processMetricsTop = 5
+)
+
+// Metrics descriptions
+var (
+	osTimestamp = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "timestamp_seconds"),
+		"Timestamp of the newest Enhanced Monitoring payload of the RDS instance",
+		labels,
+		nil,
+	)
+
+	osCPUUtilization = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "cpu_utilization_percent"),
+		"Percentage of CPU in use by mode",
+		[]string{"region", "instance", "mode"},
+		nil,
+	)
+
+	osLoad1 = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "load1"),
+		"Number of processes requesting CPU time over the last minute",
+		labels,
+		nil,
+	)
+
+	osLoad5 = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "load5"),
+		"Number of processes requesting CPU time over the last 5 minutes",
+		labels,
+		nil,
+	)
+
+	osLoad15 = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "load15"),
+		"Number of processes requesting CPU time over the last 15 minutes",
+		labels,
+		nil,
+	)
+
+	osMemory = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "memory_bytes"),
+		"Memory of the RDS instance in bytes by type",
+		[]string{"region", "instance", "type"},
+		nil,
+	)
+
+	osSwap = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "swap_bytes"),
+		"Swap of the RDS instance in bytes by type",
+		[]string{"region", "instance", "type"},
+		nil,
+	)
+
+	osSwapIn = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "swap_in_bytes_per_second"),
+		"Bytes swapped in per second",
+		labels,
+		nil,
+	)
+
+	osSwapOut = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "swap_out_bytes_per_second"),
+		"Bytes swapped out per second",
+		labels,
+		nil,
+	)
+
+	osDiskLabels = []string{"region", "instance", "device"}
+
+	osDiskReadBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "disk_read_bytes_per_second"),
+		"Bytes read per second from the device",
+		osDiskLabels,
+		nil,
+	)
+
+	osDiskWriteBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "disk_write_bytes_per_second"),
+		"Bytes written per second to the device",
+		osDiskLabels,
+		nil,
+	)
+
+	osDiskReadIOPS = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "disk_read_iops"),
+		"Read operations per second of the device",
+		osDiskLabels,
+		nil,
+	)
+
+	osDiskWriteIOPS = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "disk_write_iops"),
+		"Write operations per second of the device",
+		osDiskLabels,
+		nil,
+	)
+
+	osDiskAwait = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "disk_await_seconds"),
+		"Average time to respond to requests of the device",
+		osDiskLabels,
+		nil,
+	)
+
+	osDiskQueueLength = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "disk_queue_length"),
+		"Average number of requests waiting in the queue of the device",
+		osDiskLabels,
+		nil,
+	)
+
+	osDiskUtilization = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "disk_utilization_percent"),
+		"Percentage of CPU time during which requests were issued to the device",
+		osDiskLabels,
+		nil,
+	)
+
+	osNetworkLabels = []string{"region", "instance", "interface"}
+
+	osNetworkReceive = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "network_receive_bytes_per_second"),
+		"Bytes received per second on the interface",
+		osNetworkLabels,
+		nil,
+	)
+
+	osNetworkTransmit = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "network_transmit_bytes_per_second"),
+		"Bytes transmitted per second on the interface",
+		osNetworkLabels,
+		nil,
+	)
+
+	osFileSystemLabels = []string{"region", "instance", "name", "mount_point"}
+
+	osFileSystemSize = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "filesystem_size_bytes"),
+		"Size of the file system in bytes",
+		osFileSystemLabels,
+		nil,
+	)
+
+	osFileSystemUsed = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "filesystem_used_bytes"),
+		"Space used on the file system in bytes",
+		osFileSystemLabels,
+		nil,
+	)
+
+	osFileSystemFiles = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "filesystem_files"),
+		"Maximum number of files of the file system",
+		osFileSystemLabels,
+		nil,
+	)
+
+	osFileSystemUsedFiles = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "filesystem_used_files"),
+		"Number of files on the file system",
+		osFileSystemLabels,
+		nil,
+	)
+
+	osProcessLabels = []string{"region", "instance", "rank", "process"}
+
+	osProcessCPU = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "top_process_cpu_used_percent"),
+		"Percentage of CPU used by the process, for the 5 processes using the most CPU",
+		osProcessLabels,
+		nil,
+	)
+
+	osProcessMemory = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, osSubsystem, "top_process_memory_used_percent"),
+		"Percentage of memory used by the process, for the 5 processes using the most CPU",
+		osProcessLabels,
+		nil,
+	)
+)
+
+// osMetrics is the Enhanced Monitoring payload, as documented in
+// https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/USER_Monitoring-Available-OS-Metrics.html
+type osMetrics struct {
+	Engine         string    `json:"engine"`
+	InstanceID     string    `json:"instanceID"`
+	Timestamp      time.Time `json:"timestamp"`
+	CPUUtilization struct {
+		Guest  float64 `json:"guest"`
+		Irq    float64 `json:"irq"`
+		System float64 `json:"system"`
+		Wait   float64 `json:"wait"`
+		Idle   float64 `json:"idle"`
+		User   float64 `json:"user"`
+		Total  float64 `json:"total"`
+		Steal  float64 `json:"steal"`
+		Nice   float64 `json:"nice"`
+	} `json:"cpuUtilization"`
+	LoadAverageMinute struct {
+		One     float64 `json:"one"`
+		Five    float64 `json:"five"`
+		Fifteen float64 `json:"fifteen"`
+	} `json:"loadAverageMinute"`
+	Memory map[string]float64 `json:"memory"`
+	Swap   struct {
+		Cached float64 `json:"cached"`
+		Total  float64 `json:"total"`
+		Free   float64 `json:"free"`
+		In     float64 `json:"in"`
+		Out    float64 `json:"out"`
+	} `json:"swap"`
+	Network []struct {
+		Interface string  `json:"interface"`
+		Rx        float64 `json:"rx"`
+		Tx        float64 `json:"tx"`
+	} `json:"network"`
+	DiskIO []struct {
+		Device      string  `json:"device"`
+		ReadKbPS    float64 `json:"readKbPS"`
+		WriteKbPS   float64 `json:"writeKbPS"`
+		ReadIOsPS   float64 `json:"readIOsPS"`
+		WriteIOsPS  float64 `json:"writeIOsPS"`
+		Await       float64 `json:"await"`
+		AvgQueueLen float64 `json:"avgQueueLen"`
+		Util        float64 `json:"util"`
+	} `json:"diskIO"`
+	FileSys []struct {
+		Name       string  `json:"name"`
+		MountPoint string  `json:"mountPoint"`
+		Total      float64 `json:"total"`
+		Used       float64 `json:"used"`
+		MaxFiles   float64 `json:"maxFiles"`
+		UsedFiles  float64 `json:"usedFiles"`
+	} `json:"fileSys"`
+	ProcessList []osProcess `json:"processList"`
+}
+
+// osProcess is a process of the processList of the Enhanced Monitoring payload
+type osProcess struct {
+	Name         string  `json:"name"`
+	ID           int64   `json:"id"`
+	ParentID     int64   `json:"parentID"`
+	Tgid         int64   `json:"tgid"`
+	CPUUsedPc    float64 `json:"cpuUsedPc"`
+	MemoryUsedPc float64 `json:"memoryUsedPc"`
+	Rss          float64 `json:"rss"`
+	Vss          float64 `json:"vss"`
+}
+
+// topProcesses returns the n processes using the most CPU, then memory
+func topProcesses(ps []osProcess, n int) []osProcess {
+	top := make([]osProcess, len(ps))
+	copy(top, ps)
+	sort.SliceStable(top, func(i, j int) bool {
+		if top[i].CPUUsedPc != top[j].CPUUsedPc {
+			return top[i].CPUUsedPc > top[j].CPUUsedPc
+		}
+		return top[i].MemoryUsedPc > top[j].MemoryUsedPc
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// osMemoryTypes maps the memory fields of the payload to the type label
+var osMemoryTypes = map[string]string{
+	"total":      "total",
+	"free":       "free",
+	"cached":     "cached",
+	"buffers":    "buffers",
+	"active":     "active",
+	"inactive":   "inactive",
+	"dirty":      "dirty",
+	"writeback":  "writeback",
+	"mapped":     "mapped",
+	"slab":       "slab",
+	"pageTables": "page_tables",
+}
+
+// CloudWatchLogsClient is a wrapper for AWS cloudwatchlogs client that implements helpers to get Enhanced Monitoring data
+type CloudWatchLogsClient struct {
+	client cloudwatchlogsiface.CloudWatchLogsAPI
+}
+
+// EnhancedMonitoringGatherer is the interface that implements the methods required to gather Enhanced Monitoring data
+type EnhancedMonitoringGatherer interface {
+	GetEnhancedMonitoringMessage(resourceID string) (string, error)
+}
+
+// NewCloudWatchLogsClient will return an initialized CloudWatchLogsClient
+func NewCloudWatchLogsClient(awsRegion string) (*CloudWatchLogsClient, error) {
+	// Create AWS session
+	s := session.New(&aws.Config{Region: aws.String(awsRegion)})
+	if s == nil {
+		return nil, fmt.Errorf("error creating aws session")
+	}
+
+	return &CloudWatchLogsClient{
+		client: cloudwatchlogs.New(s),
+	}, nil
+}
+
+// GetEnhancedMonitoringMessage will get the newest Enhanced Monitoring
+// payload of the instance from the CloudWatch Logs API. It returns an empty
+// message if the instance has not published any yet.
+func (e *CloudWatchLogsClient) GetEnhancedMonitoringMessage(resourceID string) (string, error) {
+	params := &cloudwatchlogs.GetLogEventsInput{
+		LogGroupName:  aws.String(enhancedMonitoringLogGroup),
+		LogStreamName: aws.String(resourceID),
+		Limit:         aws.Int64(1),
+		StartFromHead: aws.Bool(false),
+	}
+
+	resp, err := e.client.GetLogEvents(params)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == cloudwatchlogs.ErrCodeResourceNotFoundException {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if len(resp.Events) == 0 {
+		return "", nil
+	}
+	return aws.StringValue(resp.Events[len(resp.Events)-1].Message), nil
+}
+
+// NewEnhancedMonitoringCollector returns a collector for the Enhanced
+// Monitoring OS metrics of the RDS instances with a MonitoringInterval. top
+// is the default number of processes served by ServeHTTP, processMetrics
+// enables the metrics of the 5 processes using the most CPU.
+func NewEnhancedMonitoringCollector(rdsClient RDSGatherer, client EnhancedMonitoringGatherer, awsRegion string, top int, processMetrics bool, logger log.Logger) (*enhancedMonitoringCollector, error) {
+	if top < 1 {
+		return nil, fmt.Errorf("invalid number of top processes %d, must be at least 1", top)
+	}
+
+	return &enhancedMonitoringCollector{
+		rdsClient:      rdsClient,
+		client:         client,
+		region:         awsRegion,
+		top:            top,
+		processMetrics: processMetrics,
+		payloads:       map[string]*osMetrics{},
+		logger:         logger,
+	}, nil
+}
+
+type enhancedMonitoringCollector struct {
+	rdsClient      RDSGatherer
+	client         EnhancedMonitoringGatherer
+	region         string
+	top            int
+	processMetrics bool
+	logger         log.Logger
+
+	mu       sync.Mutex
+	payloads map[string]*osMetrics
+}
+
+// Describe describes the metrics exported by the Enhanced Monitoring
+// collector. It implements prometheus.Collector.
+func (c *enhancedMonitoringCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- osTimestamp
+	ch <- osCPUUtilization
+	ch <- osLoad1
+	ch <- osLoad5
+	ch <- osLoad15
+	ch <- osMemory
+	ch <- osSwap
+	ch <- osSwapIn
+	ch <- osSwapOut
+	ch <- osDiskReadBytes
+	ch <- osDiskWriteBytes
+	ch <- osDiskReadIOPS
+	ch <- osDiskWriteIOPS
+	ch <- osDiskAwait
+	ch <- osDiskQueueLength
+	ch <- osDiskUtilization
+	ch <- osNetworkReceive
+	ch <- osNetworkTransmit
+	ch <- osFileSystemSize
+	ch <- osFileSystemUsed
+	ch <- osFileSystemFiles
+	ch <- osFileSystemUsedFiles
+	if c.processMetrics {
+		ch <- osProcessCPU
+		ch <- osProcessMemory
+	}
+}
+
+// Collect fetches the newest Enhanced Monitoring payload of every RDS
+// instance and delivers it as Prometheus metrics. It implements
+// prometheus.Collector
+func (c *enhancedMonitoringCollector) Collect(ch chan<- prometheus.Metric) {
+	rs, err := c.rdsClient.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+
+	// payloads of deleted instances, or instances with monitoring turned
+	// off, must not be served anymore
+	payloads := map[string]*osMetrics{}
+	defer func() {
+		c.mu.Lock()
+		c.payloads = payloads
+		c.mu.Unlock()
+	}()
+
+	for _, r := range rs {
+		if r.MonitoringInterval == 0 || r.ResourceID == "" {
+			continue
+		}
+
+		message, err := c.client.GetEnhancedMonitoringMessage(r.ResourceID)
+		if err != nil {
+			level.Error(c.logger).Log("msg", "Error getting Enhanced Monitoring payload", "instance", r.Identifier, "err", err)
+			continue
+		}
+		if message == "" {
+			continue
+		}
+
+		m := &osMetrics{}
+		if err := json.Unmarshal([]byte(message), m); err != nil {
+			level.Error(c.logger).Log("msg", "Error parsing Enhanced Monitoring payload", "instance", r.Identifier, "err", err)
+			continue
+		}
+
+		payloads[r.Identifier] = m
+		c.collectPayload(ch, r.Identifier, m)
+	}
+}
+
+// collectPayload sends the metrics of a single payload
+func (c *enhancedMonitoringCollector) collectPayload(ch chan<- prometheus.Metric, instance string, m *osMetrics) {
+	ch <- prometheus.MustNewConstMetric(osTimestamp, prometheus.GaugeValue, float64(m.Timestamp.Unix()), c.region, instance)
+
+	cpu := map[string]float64{
+		"guest":  m.CPUUtilization.Guest,
+		"irq":    m.CPUUtilization.Irq,
+		"system": m.CPUUtilization.System,
+		"wait":   m.CPUUtilization.Wait,
+		"idle":   m.CPUUtilization.Idle,
+		"user":   m.CPUUtilization.User,
+		"total":  m.CPUUtilization.Total,
+		"steal":  m.CPUUtilization.Steal,
+		"nice":   m.CPUUtilization.Nice,
+	}
+	for mode, v := range cpu {
+		ch <- prometheus.MustNewConstMetric(osCPUUtilization, prometheus.GaugeValue, v, c.region, instance, mode)
+	}
+
+	ch <- prometheus.MustNewConstMetric(osLoad1, prometheus.GaugeValue, m.LoadAverageMinute.One, c.region, instance)
+	ch <- prometheus.MustNewConstMetric(osLoad5, prometheus.GaugeValue, m.LoadAverageMinute.Five, c.region, instance)
+	ch <- prometheus.MustNewConstMetric(osLoad15, prometheus.GaugeValue, m.LoadAverageMinute.Fifteen, c.region, instance)
+
+	for field, t := range osMemoryTypes {
+		if v, ok := m.Memory[field]; ok {
+			ch <- prometheus.MustNewConstMetric(osMemory, prometheus.GaugeValue, v*kibibyte, c.region, instance, t)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(osSwap, prometheus.GaugeValue, m.Swap.Total*kibibyte, c.region, instance, "total")
+	ch <- prometheus.MustNewConstMetric(osSwap, prometheus.GaugeValue, m.Swap.Free*kibibyte, c.region, instance, "free")
+	ch <- prometheus.MustNewConstMetric(osSwap, prometheus.GaugeValue, m.Swap.Cached*kibibyte, c.region, instance, "cached")
+	ch <- prometheus.MustNewConstMetric(osSwapIn, prometheus.GaugeValue, m.Swap.In*kibibyte, c.region, instance)
+	ch <- prometheus.MustNewConstMetric(osSwapOut, prometheus.GaugeValue, m.Swap.Out*kibibyte, c.region, instance)
+
+	for _, d := range m.DiskIO {
+		ch <- prometheus.MustNewConstMetric(osDiskReadBytes, prometheus.GaugeValue, d.ReadKbPS*kibibyte, c.region, instance, d.Device)
+		ch <- prometheus.MustNewConstMetric(osDiskWriteBytes, prometheus.GaugeValue, d.WriteKbPS*kibibyte, c.region, instance, d.Device)
+		ch <- prometheus.MustNewConstMetric(osDiskReadIOPS, prometheus.GaugeValue, d.ReadIOsPS, c.region, instance, d.Device)
+		ch <- prometheus.MustNewConstMetric(osDiskWriteIOPS, prometheus.GaugeValue, d.WriteIOsPS, c.region, instance, d.Device)
+		ch <- prometheus.MustNewConstMetric(osDiskAwait, prometheus.GaugeValue, d.Await/1000, c.region, instance, d.Device)
+		ch <- prometheus.MustNewConstMetric(osDiskQueueLength, prometheus.GaugeValue, d.AvgQueueLen, c.region, instance, d.Device)
+		ch <- prometheus.MustNewConstMetric(osDiskUtilization, prometheus.GaugeValue, d.Util, c.region, instance, d.Device)
+	}
+
+	for _, n := range m.Network {
+		ch <- prometheus.MustNewConstMetric(osNetworkReceive, prometheus.GaugeValue, n.Rx, c.region, instance, n.Interface)
+		ch <- prometheus.MustNewConstMetric(osNetworkTransmit, prometheus.GaugeValue, n.Tx, c.region, instance, n.Interface)
+	}
+
+	for _, f := range m.FileSys {
+		ch <- prometheus.MustNewConstMetric(osFileSystemSize, prometheus.GaugeValue, f.Total*kibibyte, c.region, instance, f.Name, f.MountPoint)
+		ch <- prometheus.MustNewConstMetric(osFileSystemUsed, prometheus.GaugeValue, f.Used*kibibyte, c.region, instance, f.Name, f.MountPoint)
+		ch <- prometheus.MustNewConstMetric(osFileSystemFiles, prometheus.GaugeValue, f.MaxFiles, c.region, instance, f.Name, f.MountPoint)
+		ch <- prometheus.MustNewConstMetric(osFileSystemUsedFiles, prometheus.GaugeValue, f.UsedFiles, c.region, instance, f.Name, f.MountPoint)
+	}
+
+	if !c.processMetrics {
+		return
+	}
+	for i, p := range topProcesses(m.ProcessList, processMetricsTop) {
+		rank := strconv.Itoa(i + 1)
+		ch <- prometheus.MustNewConstMetric(osProcessCPU, prometheus.GaugeValue, p.CPUUsedPc, c.region, instance, rank, p.Name)
+		ch <- prometheus.MustNewConstMetric(osProcessMemory, prometheus.GaugeValue, p.MemoryUsedPc, c.region, instance, rank, p.Name)
+	}
+}
+
+// topProcessesResult is the top process list of an instance served by ServeHTTP
+type topProcessesResult struct {
+	Timestamp time.Time   `json:"timestamp"`
+	Processes []osProcess `json:"processes"`
+}
+
+// ServeHTTP writes the top processes by CPU of every instance as JSON, from
+// the payloads fetched by the last scrape. The instance and top query
+// parameters restrict the instance and the number of processes.
+func (c *enhancedMonitoringCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	top := c.top
+	if v := r.URL.Query().Get("top"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid top parameter", http.StatusBadRequest)
+			return
+		}
+		top = n
+	}
+	only := r.URL.Query().Get("instance")
+
+	c.mu.Lock()
+	result := map[string]topProcessesResult{}
+	for instance, m := range c.payloads {
+		if only != "" && instance != only {
+			continue
+		}
+		result[instance] = topProcessesResult{
+			Timestamp: m.Timestamp,
+			Processes: topProcesses(m.ProcessList, top),
+		}
+	}
+	c.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,159 @@
+package collector
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const testOSMetrics = `{
+	"engine": "MYSQL",
+	"instanceID": "db-1",
+	"instanceResourceID": "db-ABCDEFGHIJKL",
+	"timestamp": "2020-10-20T08:00:00Z",
+	"version": 1,
+	"numVCPUs": 2,
+	"cpuUtilization": {"guest": 0, "irq": 0.1, "system": 1.5, "wait": 0.4, "idle": 93, "user": 5, "total": 7, "steal": 0, "nice": 0},
+	"loadAverageMinute": {"one": 0.5, "five": 0.25, "fifteen": 0.125},
+	"memory": {"total": 8000000, "free": 1000000, "cached": 2000000, "pageTables": 1000},
+	"swap": {"cached": 0, "total": 4096, "free": 2048, "in": 1, "out": 2},
+	"network": [{"interface": "eth0", "rx": 2048, "tx": 1024}],
+	"diskIO": [{"device": "rdsdev", "readKbPS": 10, "writeKbPS": 20, "readIOsPS": 3, "writeIOsPS": 4, "await": 5, "avgQueueLen": 0.5, "util": 12}],
+	"fileSys": [{"name": "rdsfilesys", "mountPoint": "/rdsdbdata", "total": 100, "used": 25, "maxFiles": 1000, "usedFiles": 10}],
+	"processList": [
+		{"name": "mysqld", "id": 100, "cpuUsedPc": 5, "memoryUsedPc": 40, "rss": 3000000, "vss": 4000000},
+		{"name": "rdsadmin", "id": 200, "cpuUsedPc": 0.5, "memoryUsedPc": 1, "rss": 1000, "vss": 2000},
+		{"name": "backup", "id": 300, "cpuUsedPc": 12, "memoryUsedPc": 2, "rss": 2000, "vss": 3000}
+	]
+}`
+
+type fakeEnhancedMonitoringGatherer struct {
+	messages map[string]string
+}
+
+func (f *fakeEnhancedMonitoringGatherer) GetEnhancedMonitoringMessage(resourceID string) (string, error) {
+	return f.messages[resourceID], nil
+}
+
+func TestEnhancedMonitoringCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false,
+		types.DBInstance{Identifier: "db-1", ResourceID: "db-ABCDEFGHIJKL", MonitoringInterval: 60},
+		types.DBInstance{Identifier: "db-2", ResourceID: "db-MNOPQRSTUVWX"},
+		types.DBInstance{Identifier: "db-3", ResourceID: "db-YZ0123456789", MonitoringInterval: 60},
+	)
+
+	em := &fakeEnhancedMonitoringGatherer{messages: map[string]string{
+		"db-ABCDEFGHIJKL": testOSMetrics,
+		"db-MNOPQRSTUVWX": testOSMetrics,
+	}}
+
+	c, err := NewEnhancedMonitoringCollector(&RDSClient{client: mockRDS}, em, "us-east-1", 10, false, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("Shouldn't return an error, but it did: %v", err)
+	}
+
+	want := `
+# HELP aws_rds_os_disk_await_seconds Average time to respond to requests of the device
+# TYPE aws_rds_os_disk_await_seconds gauge
+aws_rds_os_disk_await_seconds{device="rdsdev",instance="db-1",region="us-east-1"} 0.005
+# HELP aws_rds_os_filesystem_used_bytes Space used on the file system in bytes
+# TYPE aws_rds_os_filesystem_used_bytes gauge
+aws_rds_os_filesystem_used_bytes{instance="db-1",mount_point="/rdsdbdata",name="rdsfilesys",region="us-east-1"} 25600
+# HELP aws_rds_os_load1 Number of processes requesting CPU time over the last minute
+# TYPE aws_rds_os_load1 gauge
+aws_rds_os_load1{instance="db-1",region="us-east-1"} 0.5
+# HELP aws_rds_os_memory_bytes Memory of the RDS instance in bytes by type
+# TYPE aws_rds_os_memory_bytes gauge
+aws_rds_os_memory_bytes{instance="db-1",region="us-east-1",type="cached"} 2.048e+09
+aws_rds_os_memory_bytes{instance="db-1",region="us-east-1",type="free"} 1.024e+09
+aws_rds_os_memory_bytes{instance="db-1",region="us-east-1",type="page_tables"} 1.024e+06
+aws_rds_os_memory_bytes{instance="db-1",region="us-east-1",type="total"} 8.192e+09
+# HELP aws_rds_os_network_receive_bytes_per_second Bytes received per second on the interface
+# TYPE aws_rds_os_network_receive_bytes_per_second gauge
+aws_rds_os_network_receive_bytes_per_second{instance="db-1",interface="eth0",region="us-east-1"} 2048
+# HELP aws_rds_os_timestamp_seconds Timestamp of the newest Enhanced Monitoring payload of the RDS instance
+# TYPE aws_rds_os_timestamp_seconds gauge
+aws_rds_os_timestamp_seconds{instance="db-1",region="us-east-1"} 1.6031808e+09
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want),
+		"aws_rds_os_disk_await_seconds", "aws_rds_os_filesystem_used_bytes", "aws_rds_os_load1",
+		"aws_rds_os_memory_bytes", "aws_rds_os_network_receive_bytes_per_second", "aws_rds_os_timestamp_seconds"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEnhancedMonitoringTopProcesses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false,
+		types.DBInstance{Identifier: "db-1", ResourceID: "db-ABCDEFGHIJKL", MonitoringInterval: 60},
+	)
+
+	em := &fakeEnhancedMonitoringGatherer{messages: map[string]string{"db-ABCDEFGHIJKL": testOSMetrics}}
+
+	c, err := NewEnhancedMonitoringCollector(&RDSClient{client: mockRDS}, em, "us-east-1", 10, true, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("Shouldn't return an error, but it did: %v", err)
+	}
+
+	want := `
+# HELP aws_rds_os_top_process_cpu_used_percent Percentage of CPU used by the process, for the 5 processes using the most CPU
+# TYPE aws_rds_os_top_process_cpu_used_percent gauge
+aws_rds_os_top_process_cpu_used_percent{instance="db-1",process="backup",rank="1",region="us-east-1"} 12
+aws_rds_os_top_process_cpu_used_percent{instance="db-1",process="mysqld",rank="2",region="us-east-1"} 5
+aws_rds_os_top_process_cpu_used_percent{instance="db-1",process="rdsadmin",rank="3",region="us-east-1"} 0.5
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "aws_rds_os_top_process_cpu_used_percent"); err != nil {
+		t.Error(err)
+	}
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/enhanced-monitoring/processes?instance=db-1&top=2", nil))
+	result := map[string]topProcessesResult{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	ps := result["db-1"].Processes
+	if len(ps) != 2 || ps[0].Name != "backup" || ps[1].Name != "mysqld" {
+		t.Errorf("Wanted backup and mysqld as top processes, got %+v", ps)
+	}
+
+	rec = httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/enhanced-monitoring/processes?top=0", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Wanted status %d for an invalid top, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	// the payload of an instance no longer monitored must not be served anymore
+	delete(em.messages, "db-ABCDEFGHIJKL")
+	testutil.CollectAndCount(c)
+	rec = httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/enhanced-monitoring/processes", nil))
+	result = map[string]topProcessesResult{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 0 {
+		t.Errorf("Wanted no instances served, got %v", result)
+	}
+}
+
+func TestEnhancedMonitoringInvalidTop(t *testing.T) {
+	if _, err := NewEnhancedMonitoringCollector(nil, nil, "us-east-1", -1, false, log.NewNopLogger()); err == nil {
+		t.Error("Should return an error for a negative top, but it didn't")
+	}
+}
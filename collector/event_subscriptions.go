@@ -0,0 +1,214 @@
+package collector
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const (
+	// eventSubscriptionActive is the status of a subscription that delivers notifications
+	eventSubscriptionActive = "active"
+
+	sourceTypeInstance = "db-instance"
+	sourceTypeCluster  = "db-cluster"
+)
+
+// coveredEventCategories are the categories every instance and cluster must have a subscription for
+var coveredEventCategories = []string{"failover", "failure"}
+
+// Metrics descriptions
+var (
+	eventSubscriptionActiveDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "event_subscription", "active"),
+		"Whether the event subscription status is active (1) or not (0)",
+		[]string{"region", "subscription", "status"},
+		nil,
+	)
+
+	eventSubscriptionEnabled = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "event_subscription", "enabled"),
+		"Whether the event subscription is enabled (1) or not (0)",
+		[]string{"region", "subscription"},
+		nil,
+	)
+
+	eventSubscriptionInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "event_subscription", "info"),
+		"Event subscription configuration, empty categories or source_ids mean all of them",
+		[]string{"region", "subscription", "source_type", "categories", "source_ids", "sns_topic_arn"},
+		nil,
+	)
+
+	eventSubscriptionCovered = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "event_subscription", "covered"),
+		"Whether at least one enabled and active event subscription covers the source for the event category",
+		[]string{"region", "source_type", "source_identifier", "category"},
+		nil,
+	)
+)
+
+// EventSubscriptionGatherer is the interface that implements the methods required to gather RDS event subscription data
+type EventSubscriptionGatherer interface {
+	RDSGatherer
+	GetRDSClusters() ([]*types.DBCluster, error)
+	GetRDSEventSubscriptions() ([]*types.EventSubscription, error)
+}
+
+// GetRDSEventSubscriptions will get the event subscriptions from the RDS API
+func (e *RDSClient) GetRDSEventSubscriptions() ([]*types.EventSubscription, error) {
+	subs := []*types.EventSubscription{}
+	params := &rds.DescribeEventSubscriptionsInput{
+		MaxRecords: aws.Int64(e.apiMaxResults),
+	}
+
+	err := e.client.DescribeEventSubscriptionsPages(params, func(page *rds.DescribeEventSubscriptionsOutput, lastPage bool) bool {
+		for _, sub := range page.EventSubscriptionsList {
+			subs = append(subs, &types.EventSubscription{
+				Name:        aws.StringValue(sub.CustSubscriptionId),
+				Status:      aws.StringValue(sub.Status),
+				Enabled:     aws.BoolValue(sub.Enabled),
+				SourceType:  aws.StringValue(sub.SourceType),
+				Categories:  aws.StringValueSlice(sub.EventCategoriesList),
+				SourceIds:   aws.StringValueSlice(sub.SourceIdsList),
+				SnsTopicArn: aws.StringValue(sub.SnsTopicArn),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return subs, nil
+}
+
+// subscriptionCovers reports whether the subscription delivers events of
+// the category for the given source
+func subscriptionCovers(sub *types.EventSubscription, sourceType, sourceID, category string) bool {
+	if !sub.Enabled || sub.Status != eventSubscriptionActive {
+		return false
+	}
+	if sub.SourceType != "" && sub.SourceType != sourceType {
+		return false
+	}
+	if len(sub.SourceIds) > 0 && !containsString(sub.SourceIds, sourceID) {
+		return false
+	}
+	if len(sub.Categories) > 0 && !containsString(sub.Categories, category) {
+		return false
+	}
+	return true
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// joinSorted returns the values sorted and comma separated, for use as a label value
+func joinSorted(values []string) string {
+	s := append([]string{}, values...)
+	sort.Strings(s)
+	return strings.Join(s, ",")
+}
+
+// NewEventSubscriptionsCollector returns a collector for the RDS event
+// subscriptions and the coverage of instances and clusters by them.
+func NewEventSubscriptionsCollector(client EventSubscriptionGatherer, awsRegion string, logger log.Logger) *eventSubscriptionsCollector {
+	return &eventSubscriptionsCollector{
+		client: client,
+		region: awsRegion,
+		logger: logger,
+	}
+}
+
+type eventSubscriptionsCollector struct {
+	client EventSubscriptionGatherer
+	region string
+	logger log.Logger
+}
+
+// Describe describes the metrics exported by the event subscriptions
+// collector. It implements prometheus.Collector.
+func (c *eventSubscriptionsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- eventSubscriptionActiveDesc
+	ch <- eventSubscriptionEnabled
+	ch <- eventSubscriptionInfo
+	ch <- eventSubscriptionCovered
+}
+
+// Collect fetches the event subscriptions and delivers them as Prometheus
+// metrics. It implements prometheus.Collector
+func (c *eventSubscriptionsCollector) Collect(ch chan<- prometheus.Metric) {
+	subs, err := c.client.GetRDSEventSubscriptions()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS event subscriptions", "err", err)
+		return
+	}
+
+	for _, sub := range subs {
+		ch <- prometheus.MustNewConstMetric(
+			eventSubscriptionActiveDesc, prometheus.GaugeValue, boolToFloat(sub.Status == eventSubscriptionActive), c.region, sub.Name, sub.Status,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			eventSubscriptionEnabled, prometheus.GaugeValue, boolToFloat(sub.Enabled), c.region, sub.Name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			eventSubscriptionInfo, prometheus.GaugeValue, 1, c.region, sub.Name, sub.SourceType, joinSorted(sub.Categories), joinSorted(sub.SourceIds), sub.SnsTopicArn,
+		)
+	}
+
+	sources := map[string][]string{}
+	instances, err := c.client.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+	for _, i := range instances {
+		sources[sourceTypeInstance] = append(sources[sourceTypeInstance], i.Identifier)
+	}
+	clusters, err := c.client.GetRDSClusters()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS clusters", "err", err)
+		return
+	}
+	for _, cl := range clusters {
+		sources[sourceTypeCluster] = append(sources[sourceTypeCluster], cl.Identifier)
+	}
+
+	for sourceType, ids := range sources {
+		for _, id := range ids {
+			for _, category := range coveredEventCategories {
+				covered := false
+				for _, sub := range subs {
+					if subscriptionCovers(sub, sourceType, id, category) {
+						covered = true
+						break
+					}
+				}
+				ch <- prometheus.MustNewConstMetric(
+					eventSubscriptionCovered, prometheus.GaugeValue, boolToFloat(covered), c.region, sourceType, id, category,
+				)
+			}
+		}
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
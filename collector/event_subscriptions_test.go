@@ -0,0 +1,64 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+func TestGetRDSEventSubscriptions(t *testing.T) {
+	sub := types.EventSubscription{
+		Name:        "prod-failover",
+		Status:      "topic-not-exist",
+		Enabled:     true,
+		SourceType:  sourceTypeInstance,
+		Categories:  []string{"failover"},
+		SnsTopicArn: "arn:aws:sns:us-east-1:123456789012:rds",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeEventSubscriptionsPages(t, mockRDS, false, sub)
+
+	e := &RDSClient{
+		client: mockRDS,
+	}
+
+	subs, err := e.GetRDSEventSubscriptions()
+	if err != nil {
+		t.Fatalf("Shouldn't return an error, but it did: %v", err)
+	}
+	if len(subs) != 1 {
+		t.Fatalf("Length in returned number of subscriptions differs than expected, want: %d; got: %d", 1, len(subs))
+	}
+	if subs[0].Name != sub.Name || subs[0].Status != sub.Status || !subs[0].Enabled {
+		t.Errorf("Wanted subscription %v, got %v", sub, *subs[0])
+	}
+}
+
+func TestSubscriptionCovers(t *testing.T) {
+	tests := []struct {
+		name    string
+		sub     types.EventSubscription
+		covered bool
+	}{
+		{"all sources and categories", types.EventSubscription{Status: "active", Enabled: true}, true},
+		{"disabled", types.EventSubscription{Status: "active", Enabled: false}, false},
+		{"topic gone", types.EventSubscription{Status: "topic-not-exist", Enabled: true}, false},
+		{"other source type", types.EventSubscription{Status: "active", Enabled: true, SourceType: sourceTypeCluster}, false},
+		{"listed source", types.EventSubscription{Status: "active", Enabled: true, SourceIds: []string{"db-1"}}, true},
+		{"other source", types.EventSubscription{Status: "active", Enabled: true, SourceIds: []string{"db-2"}}, false},
+		{"other category", types.EventSubscription{Status: "active", Enabled: true, Categories: []string{"backup"}}, false},
+	}
+
+	for _, test := range tests {
+		if got := subscriptionCovers(&test.sub, sourceTypeInstance, "db-1", "failover"); got != test.covered {
+			t.Errorf("%s: wanted covered %v, got %v", test.name, test.covered, got)
+		}
+	}
+}
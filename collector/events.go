@@ -0,0 +1,246 @@
+package collector
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const (
+	// eventsRetention is how far back the RDS API keeps events
+	eventsRetention = 14 * 24 * time.Hour
+
+	// eventsInitialLookback is how far back the first poll goes when there is no saved cursor
+	eventsInitialLookback = time.Hour
+
+	// noEventCategory is used as category label for events that carry no category
+	noEventCategory = "none"
+)
+
+// Metrics descriptions
+var (
+	eventsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "events_total"),
+		"Number of RDS events seen, by source and event category",
+		[]string{"region", "source_type", "source_identifier", "category"},
+		nil,
+	)
+
+	eventLastTimestamp = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "event", "last_timestamp_seconds"),
+		"Unix timestamp of the newest RDS event seen for the event category",
+		[]string{"region", "category"},
+		nil,
+	)
+)
+
+// EventGatherer is the interface that implements the methods required to gather RDS events
+type EventGatherer interface {
+	GetRDSEvents(since time.Time) ([]*types.DBEvent, error)
+}
+
+// GetRDSEvents will get the events emitted at or after since from the RDS API
+func (e *RDSClient) GetRDSEvents(since time.Time) ([]*types.DBEvent, error) {
+	evs := []*types.DBEvent{}
+	params := &rds.DescribeEventsInput{
+		StartTime:  aws.Time(since),
+		MaxRecords: aws.Int64(e.apiMaxResults),
+	}
+
+	err := e.client.DescribeEventsPages(params, func(page *rds.DescribeEventsOutput, lastPage bool) bool {
+		for _, ev := range page.Events {
+			evs = append(evs, &types.DBEvent{
+				SourceIdentifier: aws.StringValue(ev.SourceIdentifier),
+				SourceType:       aws.StringValue(ev.SourceType),
+				Categories:       aws.StringValueSlice(ev.EventCategories),
+				Date:             aws.TimeValue(ev.Date),
+				Message:          aws.StringValue(ev.Message),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return evs, nil
+}
+
+type eventCounterKey struct {
+	SourceType       string
+	SourceIdentifier string
+	Category         string
+}
+
+type eventCounter struct {
+	eventCounterKey
+	Count float64
+}
+
+// eventsState is what is persisted to the state file between restarts
+type eventsState struct {
+	Cursor   time.Time            `json:"cursor"`
+	Seen     []string             `json:"seen"`
+	Counters []eventCounter       `json:"counters"`
+	LastSeen map[string]time.Time `json:"last_seen"`
+}
+
+// NewEventsCollector returns a collector that polls the RDS event stream
+// incrementally. If stateFile is set, the cursor and counters are restored
+// from it and saved back after every poll that saw new events.
+func NewEventsCollector(client EventGatherer, awsRegion, stateFile string, logger log.Logger) (*eventsCollector, error) {
+	c := &eventsCollector{
+		client:    client,
+		region:    awsRegion,
+		stateFile: stateFile,
+		logger:    logger,
+		now:       time.Now,
+		seen:      map[string]bool{},
+		counts:    map[eventCounterKey]float64{},
+		lastSeen:  map[string]time.Time{},
+	}
+
+	if stateFile != "" {
+		st := eventsState{}
+		if err := loadState(stateFile, &st); err != nil {
+			return nil, fmt.Errorf("error loading events state file: %v", err)
+		}
+		c.cursor = st.Cursor
+		for _, k := range st.Seen {
+			c.seen[k] = true
+		}
+		for _, ctr := range st.Counters {
+			c.counts[ctr.eventCounterKey] = ctr.Count
+		}
+		for cat, ts := range st.LastSeen {
+			c.lastSeen[cat] = ts
+		}
+	}
+
+	return c, nil
+}
+
+type eventsCollector struct {
+	client    EventGatherer
+	region    string
+	stateFile string
+	logger    log.Logger
+	now       func() time.Time
+
+	mu sync.Mutex
+	// cursor is the timestamp of the newest event seen so far, seen holds
+	// the keys of the events at exactly that timestamp, since the API
+	// returns them again on the next poll.
+	cursor   time.Time
+	seen     map[string]bool
+	counts   map[eventCounterKey]float64
+	lastSeen map[string]time.Time
+}
+
+func eventKey(ev *types.DBEvent) string {
+	return fmt.Sprintf("%d/%s/%s/%s", ev.Date.UnixNano(), ev.SourceType, ev.SourceIdentifier, ev.Message)
+}
+
+// poll fetches the events newer than the cursor and folds them into the counters
+func (c *eventsCollector) poll() error {
+	now := c.now()
+	since := c.cursor
+	if since.IsZero() {
+		since = now.Add(-eventsInitialLookback)
+	}
+	if oldest := now.Add(-eventsRetention); since.Before(oldest) {
+		since = oldest
+	}
+
+	evs, err := c.client.GetRDSEvents(since)
+	if err != nil {
+		return err
+	}
+
+	cursor := c.cursor
+	seen := c.seen
+	changed := false
+	for _, ev := range evs {
+		if ev.Date.Before(c.cursor) || (ev.Date.Equal(c.cursor) && c.seen[eventKey(ev)]) {
+			continue
+		}
+		changed = true
+
+		if ev.Date.After(cursor) {
+			cursor = ev.Date
+			seen = map[string]bool{}
+		}
+		if ev.Date.Equal(cursor) {
+			seen[eventKey(ev)] = true
+		}
+
+		categories := ev.Categories
+		if len(categories) == 0 {
+			categories = []string{noEventCategory}
+		}
+		for _, cat := range categories {
+			c.counts[eventCounterKey{ev.SourceType, ev.SourceIdentifier, cat}]++
+			if ev.Date.After(c.lastSeen[cat]) {
+				c.lastSeen[cat] = ev.Date
+			}
+		}
+	}
+	c.cursor = cursor
+	c.seen = seen
+
+	if changed && c.stateFile != "" {
+		return saveState(c.stateFile, c.state())
+	}
+	return nil
+}
+
+func (c *eventsCollector) state() eventsState {
+	st := eventsState{
+		Cursor:   c.cursor,
+		LastSeen: c.lastSeen,
+	}
+	for k := range c.seen {
+		st.Seen = append(st.Seen, k)
+	}
+	for k, v := range c.counts {
+		st.Counters = append(st.Counters, eventCounter{k, v})
+	}
+	return st
+}
+
+// Describe describes the metrics exported by the events collector. It
+// implements prometheus.Collector.
+func (c *eventsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- eventsTotal
+	ch <- eventLastTimestamp
+}
+
+// Collect polls the RDS event stream and delivers the event counters
+// as Prometheus metrics. It implements prometheus.Collector
+func (c *eventsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.poll(); err != nil {
+		level.Error(c.logger).Log("msg", "Error polling RDS events", "err", err)
+	}
+
+	for k, v := range c.counts {
+		ch <- prometheus.MustNewConstMetric(
+			eventsTotal, prometheus.CounterValue, v, c.region, k.SourceType, k.SourceIdentifier, k.Category,
+		)
+	}
+	for cat, ts := range c.lastSeen {
+		ch <- prometheus.MustNewConstMetric(
+			eventLastTimestamp, prometheus.GaugeValue, float64(ts.Unix()), c.region, cat,
+		)
+	}
+}
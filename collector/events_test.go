@@ -0,0 +1,112 @@
+package collector
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+type fakeEventGatherer struct {
+	events []*types.DBEvent
+	since  time.Time
+}
+
+func (f *fakeEventGatherer) GetRDSEvents(since time.Time) ([]*types.DBEvent, error) {
+	f.since = since
+	return f.events, nil
+}
+
+func TestGetRDSEvents(t *testing.T) {
+	date := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	ev := types.DBEvent{
+		SourceIdentifier: "rds-dbinstance-1",
+		SourceType:       "db-instance",
+		Categories:       []string{"failover"},
+		Date:             date,
+		Message:          "Multi-AZ instance failover started.",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeEventsPages(t, mockRDS, false, ev)
+
+	e := &RDSClient{
+		client: mockRDS,
+	}
+
+	evs, err := e.GetRDSEvents(date.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Shouldn't return an error, but it did: %v", err)
+	}
+	if len(evs) != 1 {
+		t.Fatalf("Length in returned number of events differs than expected, want: %d; got: %d", 1, len(evs))
+	}
+	if evs[0].SourceIdentifier != ev.SourceIdentifier || !evs[0].Date.Equal(date) || evs[0].Categories[0] != "failover" {
+		t.Errorf("Wanted event %v, got %v", ev, *evs[0])
+	}
+}
+
+func TestEventsCollectorCursor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "events.json")
+
+	t0 := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	failover := &types.DBEvent{SourceIdentifier: "db-1", SourceType: "db-instance", Categories: []string{"failover"}, Date: t0, Message: "failover started"}
+	reboot := &types.DBEvent{SourceIdentifier: "db-2", SourceType: "db-instance", Categories: []string{"availability"}, Date: t0, Message: "DB instance restarted"}
+	later := &types.DBEvent{SourceIdentifier: "db-1", SourceType: "db-instance", Categories: []string{"failover"}, Date: t0.Add(time.Minute), Message: "failover completed"}
+
+	client := &fakeEventGatherer{events: []*types.DBEvent{failover}}
+	c, err := NewEventsCollector(client, "us-east-1", stateFile, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return t0.Add(time.Hour) }
+
+	if err := c.poll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the API returns events at the cursor again, only the new one must be counted
+	client.events = []*types.DBEvent{failover, reboot, later}
+	if err := c.poll(); err != nil {
+		t.Fatal(err)
+	}
+	if !client.since.Equal(t0) {
+		t.Errorf("Wanted the poll to start at the cursor %v, got %v", t0, client.since)
+	}
+
+	// a restarted collector must not count the same events again
+	c, err = NewEventsCollector(client, "us-east-1", stateFile, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return t0.Add(time.Hour) }
+	client.events = []*types.DBEvent{later}
+	if err := c.poll(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.counts[eventCounterKey{"db-instance", "db-1", "failover"}]; got != 2 {
+		t.Errorf("Wanted 2 failover events for db-1, got %v", got)
+	}
+	if got := c.counts[eventCounterKey{"db-instance", "db-2", "availability"}]; got != 1 {
+		t.Errorf("Wanted 1 availability event for db-2, got %v", got)
+	}
+	if got := c.lastSeen["failover"]; !got.Equal(later.Date) {
+		t.Errorf("Wanted last failover at %v, got %v", later.Date, got)
+	}
+}
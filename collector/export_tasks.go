@@ -0,0 +1,269 @@
+package collector
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+// exportTaskFinalStatuses are the statuses counted per source
+var exportTaskFinalStatuses = []string{"COMPLETE", "FAILED", "CANCELED"}
+
+// Metrics descriptions
+var (
+	exportTaskLabels = []string{"region", "task", "source_arn"}
+
+	exportTaskInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "export_task", "info"),
+		"Status of the snapshot export to S3, with the failure cause of failed exports",
+		[]string{"region", "task", "source_arn", "s3_bucket", "status", "failure_cause"},
+		nil,
+	)
+
+	exportTaskProgress = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "export_task", "progress_percent"),
+		"Progress of the snapshot export to S3 in percent",
+		exportTaskLabels,
+		nil,
+	)
+
+	exportTaskExtractedData = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "export_task", "extracted_data_bytes"),
+		"Amount of data in bytes extracted by the snapshot export to S3",
+		exportTaskLabels,
+		nil,
+	)
+
+	exportTaskStartTime = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "export_task", "start_timestamp_seconds"),
+		"Unix timestamp of the start of the snapshot export to S3",
+		exportTaskLabels,
+		nil,
+	)
+
+	exportTaskEndTime = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "export_task", "end_timestamp_seconds"),
+		"Unix timestamp of the end of the snapshot export to S3",
+		exportTaskLabels,
+		nil,
+	)
+
+	exportTasksTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "export_tasks", "total"),
+		"Number of snapshot exports to S3 of the source seen finishing, by final status",
+		[]string{"region", "source_arn", "status"},
+		nil,
+	)
+)
+
+// ExportTaskGatherer is the interface that implements the methods required to gather snapshot export data
+type ExportTaskGatherer interface {
+	GetRDSExportTasks() ([]*types.ExportTask, error)
+}
+
+// GetRDSExportTasks will get the snapshot export tasks from the RDS API
+func (e *RDSClient) GetRDSExportTasks() ([]*types.ExportTask, error) {
+	ts := []*types.ExportTask{}
+	params := &rds.DescribeExportTasksInput{
+		MaxRecords: aws.Int64(e.apiMaxResults),
+	}
+
+	err := e.client.DescribeExportTasksPages(params, func(page *rds.DescribeExportTasksOutput, lastPage bool) bool {
+		for _, t := range page.ExportTasks {
+			ts = append(ts, &types.ExportTask{
+				Identifier:      aws.StringValue(t.ExportTaskIdentifier),
+				SourceArn:       aws.StringValue(t.SourceArn),
+				S3Bucket:        aws.StringValue(t.S3Bucket),
+				Status:          aws.StringValue(t.Status),
+				PercentProgress: float64(aws.Int64Value(t.PercentProgress)),
+				// multiply by 10^9, so that it returns bytes (prometheus standard)
+				TotalExtractedData: float64(aws.Int64Value(t.TotalExtractedDataInGB)) * math.Pow(10, 9),
+				StartTime:          aws.TimeValue(t.TaskStartTime),
+				EndTime:            aws.TimeValue(t.TaskEndTime),
+				FailureCause:       aws.StringValue(t.FailureCause),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ts, nil
+}
+
+type exportTaskCounterKey struct {
+	SourceArn string
+	Status    string
+}
+
+type exportTaskCounter struct {
+	exportTaskCounterKey
+	Count float64
+}
+
+// exportTasksState is what is persisted to the state file between restarts
+type exportTasksState struct {
+	Counted  []string            `json:"counted"`
+	Counters []exportTaskCounter `json:"counters"`
+}
+
+// NewExportTasksCollector returns a collector for the snapshot exports to S3.
+// Every finished export is counted once; if stateFile is set, the counted
+// exports and counters are restored from it and saved back after every
+// scrape that counted new exports.
+func NewExportTasksCollector(client ExportTaskGatherer, awsRegion, stateFile string, logger log.Logger) (*exportTasksCollector, error) {
+	c := &exportTasksCollector{
+		client:    client,
+		region:    awsRegion,
+		stateFile: stateFile,
+		logger:    logger,
+		counted:   map[string]bool{},
+		counts:    map[exportTaskCounterKey]float64{},
+	}
+
+	if stateFile != "" {
+		st := exportTasksState{}
+		if err := loadState(stateFile, &st); err != nil {
+			return nil, fmt.Errorf("error loading export tasks state file: %v", err)
+		}
+		for _, id := range st.Counted {
+			c.counted[id] = true
+		}
+		for _, ctr := range st.Counters {
+			c.counts[ctr.exportTaskCounterKey] = ctr.Count
+		}
+	}
+
+	return c, nil
+}
+
+type exportTasksCollector struct {
+	client    ExportTaskGatherer
+	region    string
+	stateFile string
+	logger    log.Logger
+
+	mu sync.Mutex
+	// counted holds the identifiers of the listed exports already folded
+	// into counts, so they are not counted again on the next scrape.
+	counted map[string]bool
+	counts  map[exportTaskCounterKey]float64
+}
+
+// count folds the newly finished exports into the counters. Exports no
+// longer listed are forgotten, the API does not list them again.
+func (c *exportTasksCollector) count(ts []*types.ExportTask) error {
+	counted := map[string]bool{}
+	changed := false
+	for _, t := range ts {
+		if !isExportTaskFinal(t.Status) {
+			continue
+		}
+		counted[t.Identifier] = true
+		if c.counted[t.Identifier] {
+			continue
+		}
+		c.counts[exportTaskCounterKey{t.SourceArn, t.Status}]++
+		changed = true
+	}
+	if len(counted) != len(c.counted) {
+		changed = true
+	}
+	c.counted = counted
+
+	if changed && c.stateFile != "" {
+		return saveState(c.stateFile, c.state())
+	}
+	return nil
+}
+
+func (c *exportTasksCollector) state() exportTasksState {
+	st := exportTasksState{}
+	for id := range c.counted {
+		st.Counted = append(st.Counted, id)
+	}
+	for k, v := range c.counts {
+		st.Counters = append(st.Counters, exportTaskCounter{k, v})
+	}
+	return st
+}
+
+func isExportTaskFinal(status string) bool {
+	for _, s := range exportTaskFinalStatuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Describe describes the metrics exported by the export tasks collector. It
+// implements prometheus.Collector.
+func (c *exportTasksCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- exportTaskInfo
+	ch <- exportTaskProgress
+	ch <- exportTaskExtractedData
+	ch <- exportTaskStartTime
+	ch <- exportTaskEndTime
+	ch <- exportTasksTotal
+}
+
+// Collect fetches the snapshot export tasks and delivers them as Prometheus
+// metrics. It implements prometheus.Collector
+func (c *exportTasksCollector) Collect(ch chan<- prometheus.Metric) {
+	ts, err := c.client.GetRDSExportTasks()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS export tasks", "err", err)
+		return
+	}
+
+	for _, t := range ts {
+		ch <- prometheus.MustNewConstMetric(
+			exportTaskInfo, prometheus.GaugeValue, 1, c.region, t.Identifier, t.SourceArn, t.S3Bucket, t.Status, t.FailureCause,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			exportTaskProgress, prometheus.GaugeValue, t.PercentProgress, c.region, t.Identifier, t.SourceArn,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			exportTaskExtractedData, prometheus.GaugeValue, t.TotalExtractedData, c.region, t.Identifier, t.SourceArn,
+		)
+		if !t.StartTime.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				exportTaskStartTime, prometheus.GaugeValue, float64(t.StartTime.Unix()), c.region, t.Identifier, t.SourceArn,
+			)
+		}
+		if !t.EndTime.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				exportTaskEndTime, prometheus.GaugeValue, float64(t.EndTime.Unix()), c.region, t.Identifier, t.SourceArn,
+			)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.count(ts); err != nil {
+		level.Error(c.logger).Log("msg", "Error saving export tasks state", "err", err)
+	}
+
+	sources := map[string]bool{}
+	for k := range c.counts {
+		sources[k.SourceArn] = true
+	}
+	for source := range sources {
+		for _, status := range exportTaskFinalStatuses {
+			ch <- prometheus.MustNewConstMetric(
+				exportTasksTotal, prometheus.CounterValue, c.counts[exportTaskCounterKey{source, status}], c.region, source, status,
+			)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package collector
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+func TestExportTasksCollector(t *testing.T) {
+	source := "arn:aws:rds:us-east-1:123456789012:snapshot:rds:db-1-2020-10-01"
+	t0 := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeExportTasksPages(t, mockRDS, false,
+		types.ExportTask{Identifier: "export-1", SourceArn: source, S3Bucket: "lake", Status: "COMPLETE", PercentProgress: 100, TotalExtractedData: 12, StartTime: t0, EndTime: t0.Add(time.Hour)},
+		types.ExportTask{Identifier: "export-2", SourceArn: source, S3Bucket: "lake", Status: "FAILED", FailureCause: "S3 access denied", StartTime: t0.Add(24 * time.Hour)},
+	)
+
+	c, err := NewExportTasksCollector(&RDSClient{client: mockRDS}, "us-east-1", "", log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+# HELP aws_rds_export_task_extracted_data_bytes Amount of data in bytes extracted by the snapshot export to S3
+# TYPE aws_rds_export_task_extracted_data_bytes gauge
+aws_rds_export_task_extracted_data_bytes{region="us-east-1",source_arn="` + source + `",task="export-1"} 1.2e+10
+aws_rds_export_task_extracted_data_bytes{region="us-east-1",source_arn="` + source + `",task="export-2"} 0
+# HELP aws_rds_export_task_info Status of the snapshot export to S3, with the failure cause of failed exports
+# TYPE aws_rds_export_task_info gauge
+aws_rds_export_task_info{failure_cause="",region="us-east-1",s3_bucket="lake",source_arn="` + source + `",status="COMPLETE",task="export-1"} 1
+aws_rds_export_task_info{failure_cause="S3 access denied",region="us-east-1",s3_bucket="lake",source_arn="` + source + `",status="FAILED",task="export-2"} 1
+# HELP aws_rds_export_tasks_total Number of snapshot exports to S3 of the source seen finishing, by final status
+# TYPE aws_rds_export_tasks_total counter
+aws_rds_export_tasks_total{region="us-east-1",source_arn="` + source + `",status="CANCELED"} 0
+aws_rds_export_tasks_total{region="us-east-1",source_arn="` + source + `",status="COMPLETE"} 1
+aws_rds_export_tasks_total{region="us-east-1",source_arn="` + source + `",status="FAILED"} 1
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "aws_rds_export_task_extracted_data_bytes", "aws_rds_export_task_info", "aws_rds_export_tasks_total"); err != nil {
+		t.Error(err)
+	}
+
+	// the same exports listed on the next scrape must not be counted again
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "aws_rds_export_task_extracted_data_bytes", "aws_rds_export_task_info", "aws_rds_export_tasks_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+// fakeExportTaskGatherer returns the configured export tasks
+type fakeExportTaskGatherer struct {
+	tasks []*types.ExportTask
+}
+
+func (f *fakeExportTaskGatherer) GetRDSExportTasks() ([]*types.ExportTask, error) {
+	return f.tasks, nil
+}
+
+func TestExportTasksCollectorCounters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export-tasks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "export-tasks.json")
+
+	source := "arn:aws:rds:us-east-1:123456789012:snapshot:db-1"
+	running := &types.ExportTask{Identifier: "export-1", SourceArn: source, Status: "IN_PROGRESS"}
+	complete := &types.ExportTask{Identifier: "export-1", SourceArn: source, Status: "COMPLETE"}
+	failed := &types.ExportTask{Identifier: "export-2", SourceArn: source, Status: "FAILED"}
+
+	client := &fakeExportTaskGatherer{tasks: []*types.ExportTask{running, failed}}
+	c, err := NewExportTasksCollector(client, "us-east-1", stateFile, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.count(client.tasks); err != nil {
+		t.Fatal(err)
+	}
+
+	// the running export finishes and the failed one ages out of the listing
+	client.tasks = []*types.ExportTask{complete}
+	if err := c.count(client.tasks); err != nil {
+		t.Fatal(err)
+	}
+
+	// a restarted collector must not count the same exports again
+	c, err = NewExportTasksCollector(client, "us-east-1", stateFile, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.count(client.tasks); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.counts[exportTaskCounterKey{source, "COMPLETE"}]; got != 1 {
+		t.Errorf("Wanted 1 completed export, got %v", got)
+	}
+	if got := c.counts[exportTaskCounterKey{source, "FAILED"}]; got != 1 {
+		t.Errorf("Wanted 1 failed export, got %v", got)
+	}
+}
@@ -0,0 +1,186 @@
+package collector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/health"
+	"github.com/aws/aws-sdk-go/service/health/healthiface"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const (
+	// healthRegion is the region of the global AWS Health API endpoint
+	healthRegion = "us-east-1"
+
+	healthServiceRDS = "RDS"
+
+	// maxHealthEventArns is the maximum number of events DescribeAffectedEntities accepts
+	maxHealthEventArns = 10
+)
+
+// Metrics descriptions
+var (
+	healthEventLabels = []string{"region", "entity", "event_type_code", "category", "status", "event_arn"}
+
+	healthEventStart = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "health_event", "start_timestamp_seconds"),
+		"Start of the open or upcoming AWS Health event affecting the RDS instance or cluster",
+		healthEventLabels,
+		nil,
+	)
+
+	healthEventEnd = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "health_event", "end_timestamp_seconds"),
+		"End of the open or upcoming AWS Health event affecting the RDS instance or cluster, if known",
+		healthEventLabels,
+		nil,
+	)
+)
+
+// HealthClient is a wrapper for AWS Health client that implements helpers to get RDS events
+type HealthClient struct {
+	client healthiface.HealthAPI
+}
+
+// HealthGatherer is the interface that implements the methods required to gather AWS Health events
+type HealthGatherer interface {
+	GetRDSHealthEvents(awsRegion string) ([]*types.HealthEvent, error)
+}
+
+// NewHealthClient will return an initialized HealthClient. The AWS Health
+// API is served from us-east-1, the endpoint overrides it if it is not empty.
+func NewHealthClient(endpoint string) (*HealthClient, error) {
+	config := &aws.Config{Region: aws.String(healthRegion)}
+	if endpoint != "" {
+		config.Endpoint = aws.String(endpoint)
+	}
+
+	// Create AWS session
+	s := session.New(config)
+	if s == nil {
+		return nil, fmt.Errorf("error creating aws session")
+	}
+
+	return &HealthClient{
+		client: health.New(s),
+	}, nil
+}
+
+// GetRDSHealthEvents will get the open and upcoming scheduled change and
+// issue events of RDS in the region, with their affected entities, from the
+// AWS Health API
+func (e *HealthClient) GetRDSHealthEvents(awsRegion string) ([]*types.HealthEvent, error) {
+	evs := []*types.HealthEvent{}
+	byArn := map[string]*types.HealthEvent{}
+	params := &health.DescribeEventsInput{
+		Filter: &health.EventFilter{
+			Services:            aws.StringSlice([]string{healthServiceRDS}),
+			Regions:             aws.StringSlice([]string{awsRegion}),
+			EventStatusCodes:    aws.StringSlice([]string{health.EventStatusCodeOpen, health.EventStatusCodeUpcoming}),
+			EventTypeCategories: aws.StringSlice([]string{health.EventTypeCategoryScheduledChange, health.EventTypeCategoryIssue}),
+		},
+	}
+
+	err := e.client.DescribeEventsPages(params, func(page *health.DescribeEventsOutput, lastPage bool) bool {
+		for _, ev := range page.Events {
+			event := &types.HealthEvent{
+				Arn:          aws.StringValue(ev.Arn),
+				TypeCode:     aws.StringValue(ev.EventTypeCode),
+				TypeCategory: aws.StringValue(ev.EventTypeCategory),
+				Region:       aws.StringValue(ev.Region),
+				Status:       aws.StringValue(ev.StatusCode),
+				StartTime:    aws.TimeValue(ev.StartTime),
+				EndTime:      aws.TimeValue(ev.EndTime),
+			}
+			evs = append(evs, event)
+			byArn[event.Arn] = event
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(evs); i += maxHealthEventArns {
+		j := i + maxHealthEventArns
+		if j > len(evs) {
+			j = len(evs)
+		}
+		arns := []string{}
+		for _, ev := range evs[i:j] {
+			arns = append(arns, ev.Arn)
+		}
+
+		params := &health.DescribeAffectedEntitiesInput{
+			Filter: &health.EntityFilter{EventArns: aws.StringSlice(arns)},
+		}
+		err := e.client.DescribeAffectedEntitiesPages(params, func(page *health.DescribeAffectedEntitiesOutput, lastPage bool) bool {
+			for _, entity := range page.Entities {
+				if ev, ok := byArn[aws.StringValue(entity.EventArn)]; ok {
+					ev.Entities = append(ev.Entities, aws.StringValue(entity.EntityValue))
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return evs, nil
+}
+
+// NewHealthCollector returns a collector for the AWS Health events affecting
+// the RDS instances and clusters of the region. Events are cached for
+// cacheTTL, the AWS Health API has low rate limits.
+func NewHealthCollector(client HealthGatherer, awsRegion string, cacheTTL time.Duration, logger log.Logger) *healthCollector {
+	return &healthCollector{
+		client: client,
+		region: awsRegion,
+		cache:  newTTLCache(cacheTTL),
+		logger: logger,
+	}
+}
+
+type healthCollector struct {
+	client HealthGatherer
+	region string
+	cache  *ttlCache
+	logger log.Logger
+}
+
+// Describe describes the metrics exported by the health collector. It
+// implements prometheus.Collector.
+func (c *healthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- healthEventStart
+	ch <- healthEventEnd
+}
+
+// Collect fetches the AWS Health events of RDS and delivers them as
+// Prometheus metrics. It implements prometheus.Collector
+func (c *healthCollector) Collect(ch chan<- prometheus.Metric) {
+	v, err := c.cache.get("events", func() (interface{}, error) {
+		return c.client.GetRDSHealthEvents(c.region)
+	})
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting AWS Health events", "err", err)
+		return
+	}
+
+	for _, ev := range v.([]*types.HealthEvent) {
+		for _, entity := range ev.Entities {
+			labelValues := []string{c.region, entity, ev.TypeCode, ev.TypeCategory, ev.Status, ev.Arn}
+			ch <- prometheus.MustNewConstMetric(healthEventStart, prometheus.GaugeValue, float64(ev.StartTime.Unix()), labelValues...)
+			if !ev.EndTime.IsZero() {
+				ch <- prometheus.MustNewConstMetric(healthEventEnd, prometheus.GaugeValue, float64(ev.EndTime.Unix()), labelValues...)
+			}
+		}
+	}
+}
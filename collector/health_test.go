@@ -0,0 +1,100 @@
+package collector
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/health"
+	"github.com/aws/aws-sdk-go/service/health/healthiface"
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeHealthAPI serves the events and, per event ARN, the affected entities
+type fakeHealthAPI struct {
+	healthiface.HealthAPI
+	events          []*health.Event
+	entities        map[string][]string
+	eventsInput     *health.DescribeEventsInput
+	entitiesQueries int
+}
+
+func (f *fakeHealthAPI) DescribeEventsPages(input *health.DescribeEventsInput, fn func(*health.DescribeEventsOutput, bool) bool) error {
+	f.eventsInput = input
+	fn(&health.DescribeEventsOutput{Events: f.events}, true)
+	return nil
+}
+
+func (f *fakeHealthAPI) DescribeAffectedEntitiesPages(input *health.DescribeAffectedEntitiesInput, fn func(*health.DescribeAffectedEntitiesOutput, bool) bool) error {
+	f.entitiesQueries++
+	if len(input.Filter.EventArns) > maxHealthEventArns {
+		return fmt.Errorf("too many event arns: %d", len(input.Filter.EventArns))
+	}
+	out := &health.DescribeAffectedEntitiesOutput{}
+	for _, arn := range aws.StringValueSlice(input.Filter.EventArns) {
+		for _, entity := range f.entities[arn] {
+			out.Entities = append(out.Entities, &health.AffectedEntity{EventArn: aws.String(arn), EntityValue: aws.String(entity)})
+		}
+	}
+	fn(out, true)
+	return nil
+}
+
+func TestHealthCollector(t *testing.T) {
+	start := time.Date(2020, 11, 1, 6, 0, 0, 0, time.UTC)
+	api := &fakeHealthAPI{
+		events: []*health.Event{
+			{
+				Arn:               aws.String("arn:aws:health:us-east-1::event/RDS/AWS_RDS_MAINTENANCE_SCHEDULED/1"),
+				EventTypeCode:     aws.String("AWS_RDS_MAINTENANCE_SCHEDULED"),
+				EventTypeCategory: aws.String("scheduledChange"),
+				Region:            aws.String("us-east-1"),
+				StatusCode:        aws.String("upcoming"),
+				StartTime:         aws.Time(start),
+				EndTime:           aws.Time(start.Add(2 * time.Hour)),
+			},
+			{
+				Arn:               aws.String("arn:aws:health:us-east-1::event/RDS/AWS_RDS_OPERATIONAL_ISSUE/2"),
+				EventTypeCode:     aws.String("AWS_RDS_OPERATIONAL_ISSUE"),
+				EventTypeCategory: aws.String("issue"),
+				Region:            aws.String("us-east-1"),
+				StatusCode:        aws.String("open"),
+				StartTime:         aws.Time(start),
+			},
+		},
+		entities: map[string][]string{
+			"arn:aws:health:us-east-1::event/RDS/AWS_RDS_MAINTENANCE_SCHEDULED/1": {"db-1", "cluster-1"},
+			"arn:aws:health:us-east-1::event/RDS/AWS_RDS_OPERATIONAL_ISSUE/2":     {"db-2"},
+		},
+	}
+	for i := 0; i < maxHealthEventArns; i++ {
+		api.events = append(api.events, &health.Event{Arn: aws.String(fmt.Sprintf("unaffected-%d", i)), StartTime: aws.Time(start)})
+	}
+
+	c := NewHealthCollector(&HealthClient{client: api}, "us-east-1", time.Hour, log.NewNopLogger())
+
+	want := `
+# HELP aws_rds_health_event_end_timestamp_seconds End of the open or upcoming AWS Health event affecting the RDS instance or cluster, if known
+# TYPE aws_rds_health_event_end_timestamp_seconds gauge
+aws_rds_health_event_end_timestamp_seconds{category="scheduledChange",entity="cluster-1",event_arn="arn:aws:health:us-east-1::event/RDS/AWS_RDS_MAINTENANCE_SCHEDULED/1",event_type_code="AWS_RDS_MAINTENANCE_SCHEDULED",region="us-east-1",status="upcoming"} 1.6042176e+09
+aws_rds_health_event_end_timestamp_seconds{category="scheduledChange",entity="db-1",event_arn="arn:aws:health:us-east-1::event/RDS/AWS_RDS_MAINTENANCE_SCHEDULED/1",event_type_code="AWS_RDS_MAINTENANCE_SCHEDULED",region="us-east-1",status="upcoming"} 1.6042176e+09
+# HELP aws_rds_health_event_start_timestamp_seconds Start of the open or upcoming AWS Health event affecting the RDS instance or cluster
+# TYPE aws_rds_health_event_start_timestamp_seconds gauge
+aws_rds_health_event_start_timestamp_seconds{category="issue",entity="db-2",event_arn="arn:aws:health:us-east-1::event/RDS/AWS_RDS_OPERATIONAL_ISSUE/2",event_type_code="AWS_RDS_OPERATIONAL_ISSUE",region="us-east-1",status="open"} 1.6042104e+09
+aws_rds_health_event_start_timestamp_seconds{category="scheduledChange",entity="cluster-1",event_arn="arn:aws:health:us-east-1::event/RDS/AWS_RDS_MAINTENANCE_SCHEDULED/1",event_type_code="AWS_RDS_MAINTENANCE_SCHEDULED",region="us-east-1",status="upcoming"} 1.6042104e+09
+aws_rds_health_event_start_timestamp_seconds{category="scheduledChange",entity="db-1",event_arn="arn:aws:health:us-east-1::event/RDS/AWS_RDS_MAINTENANCE_SCHEDULED/1",event_type_code="AWS_RDS_MAINTENANCE_SCHEDULED",region="us-east-1",status="upcoming"} 1.6042104e+09
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+
+	if got := aws.StringValueSlice(api.eventsInput.Filter.Services); len(got) != 1 || got[0] != "RDS" {
+		t.Errorf("Wanted the events of the RDS service, got %v", got)
+	}
+	if api.entitiesQueries != 2 {
+		t.Errorf("Wanted %d DescribeAffectedEntities requests, got %d", 2, api.entitiesQueries)
+	}
+}
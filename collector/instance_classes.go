@@ -0,0 +1,83 @@
+package collector
+
+import "strings"
+
+// instanceClass holds the hardware of an RDS instance class
+type instanceClass struct {
+	MemoryGiB float64
+	VCPU      float64
+}
+
+// sizeVCPUs is the number of vCPUs of the sizes of the current generation classes
+var sizeVCPUs = map[string]float64{
+	"large":    2,
+	"xlarge":   4,
+	"2xlarge":  8,
+	"4xlarge":  16,
+	"8xlarge":  32,
+	"10xlarge": 40,
+	"12xlarge": 48,
+	"16xlarge": 64,
+	"24xlarge": 96,
+}
+
+// memoryPerVCPU is the memory in GiB per vCPU of the classes whose memory
+// scales linearly with the size
+var memoryPerVCPU = map[string]float64{
+	"m3":  3.75,
+	"m4":  4,
+	"m5":  4,
+	"m6g": 4,
+	"r3":  7.625,
+	"r4":  7.625,
+	"r5":  8,
+	"r6g": 8,
+	"x1":  15.25,
+	"x1e": 30.5,
+	"z1d": 8,
+}
+
+// burstableClasses are the classes whose memory does not scale with the vCPUs
+var burstableClasses = map[string]instanceClass{
+	"db.t2.micro":    {1, 1},
+	"db.t2.small":    {2, 1},
+	"db.t2.medium":   {4, 2},
+	"db.t2.large":    {8, 2},
+	"db.t2.xlarge":   {16, 4},
+	"db.t2.2xlarge":  {32, 8},
+	"db.t3.micro":    {1, 2},
+	"db.t3.small":    {2, 2},
+	"db.t3.medium":   {4, 2},
+	"db.t3.large":    {8, 2},
+	"db.t3.xlarge":   {16, 4},
+	"db.t3.2xlarge":  {32, 8},
+	"db.t4g.micro":   {1, 2},
+	"db.t4g.small":   {2, 2},
+	"db.t4g.medium":  {4, 2},
+	"db.t4g.large":   {8, 2},
+	"db.t4g.xlarge":  {16, 4},
+	"db.t4g.2xlarge": {32, 8},
+	"db.m3.medium":   {3.75, 1},
+}
+
+// lookupInstanceClass returns the hardware of the instance class, e.g.
+// db.r5.large, and whether the class is known
+func lookupInstanceClass(class string) (instanceClass, bool) {
+	if ic, ok := burstableClasses[class]; ok {
+		return ic, true
+	}
+
+	parts := strings.Split(class, ".")
+	if len(parts) != 3 || parts[0] != "db" {
+		return instanceClass{}, false
+	}
+	perVCPU, ok := memoryPerVCPU[parts[1]]
+	if !ok {
+		return instanceClass{}, false
+	}
+	vcpu, ok := sizeVCPUs[parts[2]]
+	if !ok {
+		return instanceClass{}, false
+	}
+	return instanceClass{MemoryGiB: perVCPU * vcpu, VCPU: vcpu}, true
+}
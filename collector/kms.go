@@ -0,0 +1,271 @@
+package collector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const (
+	kmsResourceInstance        = "instance"
+	kmsResourceCluster         = "cluster"
+	kmsResourceSnapshot        = "snapshot"
+	kmsResourceClusterSnapshot = "cluster_snapshot"
+
+	kmsUsageStorage             = "storage"
+	kmsUsagePerformanceInsights = "performance_insights"
+)
+
+// Metrics descriptions
+var (
+	kmsKeyLabels = []string{"region", "resource", "resource_type", "usage", "key_arn"}
+
+	kmsKeyInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "kms", "key_info"),
+		"State and manager of the KMS key the RDS resource is encrypted with",
+		append(kmsKeyLabels, "state", "manager"),
+		nil,
+	)
+
+	kmsKeyEnabled = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "kms", "key_enabled"),
+		"Whether the KMS key the RDS resource is encrypted with is enabled, the resource becomes inaccessible otherwise",
+		kmsKeyLabels,
+		nil,
+	)
+
+	kmsKeyDeletion = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "kms", "key_deletion_timestamp_seconds"),
+		"Scheduled deletion of the KMS key the RDS resource is encrypted with, if pending deletion",
+		kmsKeyLabels,
+		nil,
+	)
+
+	kmsKeyRotation = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "kms", "key_rotation_enabled"),
+		"Whether automatic rotation of the KMS key the RDS resource is encrypted with is enabled",
+		kmsKeyLabels,
+		nil,
+	)
+)
+
+// KMSClient is a wrapper for AWS KMS client that implements helpers to get key states
+type KMSClient struct {
+	client kmsiface.KMSAPI
+}
+
+// KMSGatherer is the interface that implements the methods required to gather KMS key data
+type KMSGatherer interface {
+	GetKMSKey(keyID string) (*types.KMSKey, error)
+}
+
+// KMSResourceGatherer is the interface that implements the methods required to gather the encrypted resources
+type KMSResourceGatherer interface {
+	RDSGatherer
+	ClusterGatherer
+	GetRDSSnapshots() ([]*types.DBSnapshot, error)
+}
+
+// NewKMSClient will return an initialized KMSClient
+func NewKMSClient(awsRegion string) (*KMSClient, error) {
+	// Create AWS session
+	s := session.New(&aws.Config{Region: aws.String(awsRegion)})
+	if s == nil {
+		return nil, fmt.Errorf("error creating aws session")
+	}
+
+	return &KMSClient{
+		client: kms.New(s),
+	}, nil
+}
+
+// GetKMSKey will get the state and rotation status of the key from the KMS API
+func (e *KMSClient) GetKMSKey(keyID string) (*types.KMSKey, error) {
+	resp, err := e.client.DescribeKey(&kms.DescribeKeyInput{KeyId: aws.String(keyID)})
+	if err != nil {
+		return nil, err
+	}
+
+	md := resp.KeyMetadata
+	key := &types.KMSKey{
+		Arn:          aws.StringValue(md.Arn),
+		State:        aws.StringValue(md.KeyState),
+		Manager:      aws.StringValue(md.KeyManager),
+		DeletionDate: aws.TimeValue(md.DeletionDate),
+	}
+
+	rotation, err := e.client.GetKeyRotationStatus(&kms.GetKeyRotationStatusInput{KeyId: md.Arn})
+	if err != nil {
+		// keys with imported material or in a state that does not allow
+		// rotation cannot report it, they are not rotated
+		if aerr, ok := err.(awserr.Error); ok &&
+			(aerr.Code() == kms.ErrCodeUnsupportedOperationException || aerr.Code() == kms.ErrCodeInvalidStateException) {
+			return key, nil
+		}
+		return nil, err
+	}
+	key.RotationEnabled = aws.BoolValue(rotation.KeyRotationEnabled)
+
+	return key, nil
+}
+
+// GetRDSSnapshots will get the instance and cluster snapshots from the RDS API
+func (e *RDSClient) GetRDSSnapshots() ([]*types.DBSnapshot, error) {
+	ss := []*types.DBSnapshot{}
+
+	params := &rds.DescribeDBSnapshotsInput{
+		Filters:    e.engineFilters(),
+		MaxRecords: aws.Int64(e.apiMaxResults),
+	}
+	err := e.client.DescribeDBSnapshotsPages(params, func(page *rds.DescribeDBSnapshotsOutput, lastPage bool) bool {
+		for _, s := range page.DBSnapshots {
+			ss = append(ss, &types.DBSnapshot{
+				Identifier: aws.StringValue(s.DBSnapshotIdentifier),
+				KmsKeyID:   aws.StringValue(s.KmsKeyId),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	clusterParams := &rds.DescribeDBClusterSnapshotsInput{
+		Filters:    e.engineFilters(),
+		MaxRecords: aws.Int64(e.apiMaxResults),
+	}
+	err = e.client.DescribeDBClusterSnapshotsPages(clusterParams, func(page *rds.DescribeDBClusterSnapshotsOutput, lastPage bool) bool {
+		for _, s := range page.DBClusterSnapshots {
+			ss = append(ss, &types.DBSnapshot{
+				Identifier: aws.StringValue(s.DBClusterSnapshotIdentifier),
+				Cluster:    true,
+				KmsKeyID:   aws.StringValue(s.KmsKeyId),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ss, nil
+}
+
+// NewKMSCollector returns a collector for the state of the KMS keys the RDS
+// instances, clusters and snapshots are encrypted with. Each distinct key is
+// described once per scrape and cached for cacheTTL.
+func NewKMSCollector(rdsClient KMSResourceGatherer, client KMSGatherer, awsRegion string, cacheTTL time.Duration, logger log.Logger) *kmsCollector {
+	return &kmsCollector{
+		rdsClient: rdsClient,
+		client:    client,
+		region:    awsRegion,
+		cache:     newTTLCache(cacheTTL),
+		logger:    logger,
+	}
+}
+
+type kmsCollector struct {
+	rdsClient KMSResourceGatherer
+	client    KMSGatherer
+	region    string
+	cache     *ttlCache
+	logger    log.Logger
+}
+
+// kmsKeyUse is a resource encrypted with a KMS key
+type kmsKeyUse struct {
+	resource     string
+	resourceType string
+	usage        string
+}
+
+// Describe describes the metrics exported by the KMS collector. It implements
+// prometheus.Collector.
+func (c *kmsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- kmsKeyInfo
+	ch <- kmsKeyEnabled
+	ch <- kmsKeyDeletion
+	ch <- kmsKeyRotation
+}
+
+// Collect gathers the KMS keys of the RDS instances, clusters and snapshots,
+// describes them and delivers their state for every resource using them as
+// Prometheus metrics. It implements prometheus.Collector
+func (c *kmsCollector) Collect(ch chan<- prometheus.Metric) {
+	keys := []string{}
+	uses := map[string][]kmsKeyUse{}
+	add := func(keyID string, use kmsKeyUse) {
+		if keyID == "" {
+			return
+		}
+		if _, ok := uses[keyID]; !ok {
+			keys = append(keys, keyID)
+		}
+		uses[keyID] = append(uses[keyID], use)
+	}
+
+	rs, err := c.rdsClient.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+	for _, r := range rs {
+		add(r.KmsKeyID, kmsKeyUse{r.Identifier, kmsResourceInstance, kmsUsageStorage})
+		add(r.PerformanceInsightsKmsKeyID, kmsKeyUse{r.Identifier, kmsResourceInstance, kmsUsagePerformanceInsights})
+	}
+
+	cs, err := c.rdsClient.GetRDSClusters()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS clusters", "err", err)
+		return
+	}
+	for _, cluster := range cs {
+		add(cluster.KmsKeyID, kmsKeyUse{cluster.Identifier, kmsResourceCluster, kmsUsageStorage})
+	}
+
+	ss, err := c.rdsClient.GetRDSSnapshots()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS snapshots", "err", err)
+		return
+	}
+	for _, s := range ss {
+		resourceType := kmsResourceSnapshot
+		if s.Cluster {
+			resourceType = kmsResourceClusterSnapshot
+		}
+		add(s.KmsKeyID, kmsKeyUse{s.Identifier, resourceType, kmsUsageStorage})
+	}
+
+	for _, keyID := range keys {
+		v, err := c.cache.get(keyID, func() (interface{}, error) {
+			return c.client.GetKMSKey(keyID)
+		})
+		if err != nil {
+			level.Error(c.logger).Log("msg", "Error getting KMS key", "key", keyID, "err", err)
+			continue
+		}
+		key := v.(*types.KMSKey)
+
+		for _, use := range uses[keyID] {
+			labels := []string{c.region, use.resource, use.resourceType, use.usage, key.Arn}
+
+			ch <- prometheus.MustNewConstMetric(kmsKeyInfo, prometheus.GaugeValue, 1, append(labels, key.State, key.Manager)...)
+			ch <- prometheus.MustNewConstMetric(kmsKeyEnabled, prometheus.GaugeValue, boolToFloat(key.State == kms.KeyStateEnabled), labels...)
+			ch <- prometheus.MustNewConstMetric(kmsKeyRotation, prometheus.GaugeValue, boolToFloat(key.RotationEnabled), labels...)
+			if !key.DeletionDate.IsZero() {
+				ch <- prometheus.MustNewConstMetric(kmsKeyDeletion, prometheus.GaugeValue, float64(key.DeletionDate.Unix()), labels...)
+			}
+		}
+	}
+}
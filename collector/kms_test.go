@@ -0,0 +1,109 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+// fakeKMSAPI serves the key metadata and rotation status per key ARN
+type fakeKMSAPI struct {
+	kmsiface.KMSAPI
+	keys         map[string]*kms.KeyMetadata
+	rotation     map[string]bool
+	describeKeys int
+}
+
+func (f *fakeKMSAPI) DescribeKey(input *kms.DescribeKeyInput) (*kms.DescribeKeyOutput, error) {
+	f.describeKeys++
+	return &kms.DescribeKeyOutput{KeyMetadata: f.keys[aws.StringValue(input.KeyId)]}, nil
+}
+
+func (f *fakeKMSAPI) GetKeyRotationStatus(input *kms.GetKeyRotationStatusInput) (*kms.GetKeyRotationStatusOutput, error) {
+	enabled, ok := f.rotation[aws.StringValue(input.KeyId)]
+	if !ok {
+		return nil, awserr.New(kms.ErrCodeUnsupportedOperationException, "imported key material", nil)
+	}
+	return &kms.GetKeyRotationStatusOutput{KeyRotationEnabled: aws.Bool(enabled)}, nil
+}
+
+func TestKMSCollector(t *testing.T) {
+	const (
+		keyA = "arn:aws:kms:us-east-1:123456789012:key/a"
+		keyB = "arn:aws:kms:us-east-1:123456789012:key/b"
+		keyC = "arn:aws:kms:us-east-1:123456789012:key/c"
+	)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false,
+		types.DBInstance{Identifier: "db-1", KmsKeyID: keyA, PerformanceInsightsKmsKeyID: keyB},
+		types.DBInstance{Identifier: "db-2"},
+	)
+	awsMock.MockDescribeDBClustersPages(t, mockRDS, false,
+		types.DBCluster{Identifier: "aurora-1", KmsKeyID: keyC},
+	)
+	awsMock.MockDescribeDBSnapshotsPages(t, mockRDS, false,
+		types.DBSnapshot{Identifier: "db-1-snap", KmsKeyID: keyA},
+		types.DBSnapshot{Identifier: "aurora-1-snap", Cluster: true, KmsKeyID: keyC},
+	)
+
+	api := &fakeKMSAPI{
+		keys: map[string]*kms.KeyMetadata{
+			keyA: {Arn: aws.String(keyA), KeyState: aws.String("Enabled"), KeyManager: aws.String("CUSTOMER")},
+			keyB: {Arn: aws.String(keyB), KeyState: aws.String("Disabled"), KeyManager: aws.String("CUSTOMER")},
+			keyC: {
+				Arn:          aws.String(keyC),
+				KeyState:     aws.String("PendingDeletion"),
+				KeyManager:   aws.String("CUSTOMER"),
+				DeletionDate: aws.Time(time.Date(2020, 11, 8, 0, 0, 0, 0, time.UTC)),
+			},
+		},
+		rotation: map[string]bool{keyA: true, keyB: false},
+	}
+
+	c := NewKMSCollector(&RDSClient{client: mockRDS}, &KMSClient{client: api}, "us-east-1", time.Hour, log.NewNopLogger())
+
+	want := `
+# HELP aws_rds_kms_key_deletion_timestamp_seconds Scheduled deletion of the KMS key the RDS resource is encrypted with, if pending deletion
+# TYPE aws_rds_kms_key_deletion_timestamp_seconds gauge
+aws_rds_kms_key_deletion_timestamp_seconds{key_arn="arn:aws:kms:us-east-1:123456789012:key/c",region="us-east-1",resource="aurora-1",resource_type="cluster",usage="storage"} 1.6047936e+09
+aws_rds_kms_key_deletion_timestamp_seconds{key_arn="arn:aws:kms:us-east-1:123456789012:key/c",region="us-east-1",resource="aurora-1-snap",resource_type="cluster_snapshot",usage="storage"} 1.6047936e+09
+# HELP aws_rds_kms_key_enabled Whether the KMS key the RDS resource is encrypted with is enabled, the resource becomes inaccessible otherwise
+# TYPE aws_rds_kms_key_enabled gauge
+aws_rds_kms_key_enabled{key_arn="arn:aws:kms:us-east-1:123456789012:key/a",region="us-east-1",resource="db-1",resource_type="instance",usage="storage"} 1
+aws_rds_kms_key_enabled{key_arn="arn:aws:kms:us-east-1:123456789012:key/a",region="us-east-1",resource="db-1-snap",resource_type="snapshot",usage="storage"} 1
+aws_rds_kms_key_enabled{key_arn="arn:aws:kms:us-east-1:123456789012:key/b",region="us-east-1",resource="db-1",resource_type="instance",usage="performance_insights"} 0
+aws_rds_kms_key_enabled{key_arn="arn:aws:kms:us-east-1:123456789012:key/c",region="us-east-1",resource="aurora-1",resource_type="cluster",usage="storage"} 0
+aws_rds_kms_key_enabled{key_arn="arn:aws:kms:us-east-1:123456789012:key/c",region="us-east-1",resource="aurora-1-snap",resource_type="cluster_snapshot",usage="storage"} 0
+# HELP aws_rds_kms_key_rotation_enabled Whether automatic rotation of the KMS key the RDS resource is encrypted with is enabled
+# TYPE aws_rds_kms_key_rotation_enabled gauge
+aws_rds_kms_key_rotation_enabled{key_arn="arn:aws:kms:us-east-1:123456789012:key/a",region="us-east-1",resource="db-1",resource_type="instance",usage="storage"} 1
+aws_rds_kms_key_rotation_enabled{key_arn="arn:aws:kms:us-east-1:123456789012:key/a",region="us-east-1",resource="db-1-snap",resource_type="snapshot",usage="storage"} 1
+aws_rds_kms_key_rotation_enabled{key_arn="arn:aws:kms:us-east-1:123456789012:key/b",region="us-east-1",resource="db-1",resource_type="instance",usage="performance_insights"} 0
+aws_rds_kms_key_rotation_enabled{key_arn="arn:aws:kms:us-east-1:123456789012:key/c",region="us-east-1",resource="aurora-1",resource_type="cluster",usage="storage"} 0
+aws_rds_kms_key_rotation_enabled{key_arn="arn:aws:kms:us-east-1:123456789012:key/c",region="us-east-1",resource="aurora-1-snap",resource_type="cluster_snapshot",usage="storage"} 0
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want),
+		"aws_rds_kms_key_deletion_timestamp_seconds", "aws_rds_kms_key_enabled", "aws_rds_kms_key_rotation_enabled",
+	); err != nil {
+		t.Error(err)
+	}
+
+	if api.describeKeys != 3 {
+		t.Errorf("Wanted each of the %d keys described once, got %d DescribeKey requests", 3, api.describeKeys)
+	}
+}
@@ -0,0 +1,144 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+// Metrics descriptions
+var (
+	logFilesCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "log_files"),
+		"Number of log files of the RDS instance",
+		labels,
+		nil,
+	)
+
+	logFilesSize = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "log_files", "size_bytes"),
+		"Total size in bytes of the log files of the RDS instance",
+		labels,
+		nil,
+	)
+
+	logFileLargest = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "log_file", "largest_size_bytes"),
+		"Size in bytes of the largest log file of the RDS instance",
+		labels,
+		nil,
+	)
+
+	logFileLastWritten = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "log_file", "last_written_timestamp_seconds"),
+		"Unix timestamp of the last write to the newest log file of the RDS instance",
+		labels,
+		nil,
+	)
+)
+
+// LogFileGatherer is the interface that implements the methods required to gather RDS log file data
+type LogFileGatherer interface {
+	RDSGatherer
+	GetRDSLogFiles(instance string) ([]*types.DBLogFile, error)
+}
+
+// GetRDSLogFiles will get the log files of the instance from the RDS API
+func (e *RDSClient) GetRDSLogFiles(instance string) ([]*types.DBLogFile, error) {
+	fs := []*types.DBLogFile{}
+	params := &rds.DescribeDBLogFilesInput{
+		DBInstanceIdentifier: aws.String(instance),
+		MaxRecords:           aws.Int64(e.apiMaxResults),
+	}
+
+	err := e.client.DescribeDBLogFilesPages(params, func(page *rds.DescribeDBLogFilesOutput, lastPage bool) bool {
+		for _, f := range page.DescribeDBLogFiles {
+			fs = append(fs, &types.DBLogFile{
+				Name:        aws.StringValue(f.LogFileName),
+				Size:        float64(aws.Int64Value(f.Size)),
+				LastWritten: aws.MillisecondsTimeValue(f.LastWritten),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fs, nil
+}
+
+// NewLogFilesCollector returns a collector for the log file inventory of the RDS instances
+func NewLogFilesCollector(client LogFileGatherer, awsRegion string, logger log.Logger) *logFilesCollector {
+	return &logFilesCollector{
+		client: client,
+		region: awsRegion,
+		logger: logger,
+	}
+}
+
+type logFilesCollector struct {
+	client LogFileGatherer
+	region string
+	logger log.Logger
+}
+
+// Describe describes the metrics exported by the log files collector. It
+// implements prometheus.Collector.
+func (c *logFilesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- logFilesCount
+	ch <- logFilesSize
+	ch <- logFileLargest
+	ch <- logFileLastWritten
+}
+
+// Collect fetches the log files of every RDS instance and delivers them as
+// Prometheus metrics. It implements prometheus.Collector
+func (c *logFilesCollector) Collect(ch chan<- prometheus.Metric) {
+	rs, err := c.client.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+
+	for _, r := range rs {
+		fs, err := c.client.GetRDSLogFiles(r.Identifier)
+		if err != nil {
+			level.Error(c.logger).Log("msg", "Error getting RDS log files", "instance", r.Identifier, "err", err)
+			continue
+		}
+
+		var total, largest float64
+		var newest time.Time
+		for _, f := range fs {
+			total += f.Size
+			if f.Size > largest {
+				largest = f.Size
+			}
+			if f.LastWritten.After(newest) {
+				newest = f.LastWritten
+			}
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			logFilesCount, prometheus.GaugeValue, float64(len(fs)), c.region, r.Identifier,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			logFilesSize, prometheus.GaugeValue, total, c.region, r.Identifier,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			logFileLargest, prometheus.GaugeValue, largest, c.region, r.Identifier,
+		)
+		if len(fs) > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				logFileLastWritten, prometheus.GaugeValue, float64(newest.Unix()), c.region, r.Identifier,
+			)
+		}
+	}
+}
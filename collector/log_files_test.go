@@ -0,0 +1,49 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+func TestLogFilesCollector(t *testing.T) {
+	instance := types.DBInstance{Identifier: "rds-dbinstance-1", AllocatedStorage: 55.0}
+	t0 := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false, instance)
+	awsMock.MockDescribeDBLogFilesPages(t, mockRDS, false,
+		types.DBLogFile{Name: "error/postgresql.log.2020-10-01-11", Size: 1024, LastWritten: t0.Add(-time.Hour)},
+		types.DBLogFile{Name: "error/postgresql.log.2020-10-01-12", Size: 4096, LastWritten: t0},
+	)
+
+	c := NewLogFilesCollector(&RDSClient{client: mockRDS}, "us-east-1", log.NewNopLogger())
+
+	want := `
+# HELP aws_rds_log_file_largest_size_bytes Size in bytes of the largest log file of the RDS instance
+# TYPE aws_rds_log_file_largest_size_bytes gauge
+aws_rds_log_file_largest_size_bytes{instance="rds-dbinstance-1",region="us-east-1"} 4096
+# HELP aws_rds_log_file_last_written_timestamp_seconds Unix timestamp of the last write to the newest log file of the RDS instance
+# TYPE aws_rds_log_file_last_written_timestamp_seconds gauge
+aws_rds_log_file_last_written_timestamp_seconds{instance="rds-dbinstance-1",region="us-east-1"} 1.6015536e+09
+# HELP aws_rds_log_files Number of log files of the RDS instance
+# TYPE aws_rds_log_files gauge
+aws_rds_log_files{instance="rds-dbinstance-1",region="us-east-1"} 2
+# HELP aws_rds_log_files_size_bytes Total size in bytes of the log files of the RDS instance
+# TYPE aws_rds_log_files_size_bytes gauge
+aws_rds_log_files_size_bytes{instance="rds-dbinstance-1",region="us-east-1"} 5120
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,399 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+// errorLogPrefixes are the name prefixes of the error logs of the engines:
+// PostgreSQL, MySQL and MariaDB, Oracle and SQL Server
+var errorLogPrefixes = []string{"error/", "trace/alert_", "log/ERROR"}
+
+// Metrics descriptions
+var (
+	logPatternMatches = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "log", "pattern_matches_total"),
+		"Number of error log lines of the RDS instance matching the pattern",
+		[]string{"region", "instance", "pattern"},
+		nil,
+	)
+
+	logTailBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "log_tail", "bytes_total"),
+		"Number of error log bytes of the RDS instance downloaded by the log tailer",
+		labels,
+		nil,
+	)
+)
+
+// LogTailGatherer is the interface that implements the methods required to tail RDS log files
+type LogTailGatherer interface {
+	LogFileGatherer
+	DownloadRDSLogFilePortion(instance, file, marker string, lines int64) (*types.DBLogFilePortion, error)
+}
+
+// DownloadRDSLogFilePortion will download the portion of the log file
+// starting at marker from the RDS API. If marker is empty, the last lines
+// of the file are returned.
+func (e *RDSClient) DownloadRDSLogFilePortion(instance, file, marker string, lines int64) (*types.DBLogFilePortion, error) {
+	params := &rds.DownloadDBLogFilePortionInput{
+		DBInstanceIdentifier: aws.String(instance),
+		LogFileName:          aws.String(file),
+	}
+	if marker != "" {
+		params.Marker = aws.String(marker)
+	}
+	if lines > 0 {
+		params.NumberOfLines = aws.Int64(lines)
+	}
+
+	resp, err := e.client.DownloadDBLogFilePortion(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.DBLogFilePortion{
+		Data:                  aws.StringValue(resp.LogFileData),
+		Marker:                aws.StringValue(resp.Marker),
+		AdditionalDataPending: aws.BoolValue(resp.AdditionalDataPending),
+	}, nil
+}
+
+type logPattern struct {
+	name   string
+	regexp *regexp.Regexp
+}
+
+// logMarker is where the tailer stopped reading the error log of an instance
+type logMarker struct {
+	File   string  `json:"file"`
+	Marker string  `json:"marker"`
+	Size   float64 `json:"size"`
+}
+
+type logCounterKey struct {
+	Instance string
+	Pattern  string
+}
+
+type logCounter struct {
+	logCounterKey
+	Count float64
+}
+
+// logTailState is what is persisted to the state file between restarts
+type logTailState struct {
+	Markers  map[string]logMarker `json:"markers"`
+	Counters []logCounter         `json:"counters"`
+	Bytes    map[string]float64   `json:"bytes"`
+}
+
+// NewLogTailCollector returns a collector that follows the active error log
+// of every RDS instance and counts the lines matching the patterns, given
+// as name=regexp. At most maxBytes are downloaded per instance and poll.
+// If stateFile is set, markers and counters are restored from it and saved
+// back after every poll.
+func NewLogTailCollector(client LogTailGatherer, awsRegion string, patterns []string, maxBytes int, stateFile string, logger log.Logger) (*logTailCollector, error) {
+	c := &logTailCollector{
+		client:    client,
+		region:    awsRegion,
+		follower:  newLogFollower(client, maxBytes),
+		stateFile: stateFile,
+		logger:    logger,
+		counts:    map[logCounterKey]float64{},
+	}
+
+	for _, p := range patterns {
+		parts := strings.SplitN(p, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid log pattern %q, expected name=regexp", p)
+		}
+		re, err := regexp.Compile(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid log pattern %q: %v", p, err)
+		}
+		c.patterns = append(c.patterns, logPattern{name: parts[0], regexp: re})
+	}
+
+	if stateFile != "" {
+		st := logTailState{}
+		if err := loadState(stateFile, &st); err != nil {
+			return nil, fmt.Errorf("error loading log tail state file: %v", err)
+		}
+		for instance, m := range st.Markers {
+			c.follower.markers[instance] = m
+		}
+		for _, ctr := range st.Counters {
+			c.counts[ctr.logCounterKey] = ctr.Count
+		}
+		for instance, b := range st.Bytes {
+			c.follower.bytes[instance] = b
+		}
+	}
+
+	return c, nil
+}
+
+type logTailCollector struct {
+	client    LogTailGatherer
+	region    string
+	patterns  []logPattern
+	stateFile string
+	logger    log.Logger
+
+	mu       sync.Mutex
+	follower *logFollower
+	counts   map[logCounterKey]float64
+}
+
+// activeErrorLog returns the most recently written error log, or nil if the
+// instance has none
+func activeErrorLog(files []*types.DBLogFile) *types.DBLogFile {
+	var active *types.DBLogFile
+	for _, f := range files {
+		isErrorLog := false
+		for _, prefix := range errorLogPrefixes {
+			if strings.HasPrefix(f.Name, prefix) {
+				isErrorLog = true
+				break
+			}
+		}
+		if isErrorLog && (active == nil || f.LastWritten.After(active.LastWritten)) {
+			active = f
+		}
+	}
+	return active
+}
+
+// Run polls the error logs every interval, it never returns
+func (c *logTailCollector) Run(interval time.Duration) {
+	for {
+		c.poll()
+		time.Sleep(interval)
+	}
+}
+
+// poll reads the new lines of the error log of every instance
+func (c *logTailCollector) poll() {
+	rs, err := c.client.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+
+	// the logs are downloaded without holding the lock, it is only taken to
+	// update the counters, so scrapes never wait on the RDS API
+	for _, r := range rs {
+		c.mu.Lock()
+		// export the patterns with a zero count before their first match
+		for _, p := range c.patterns {
+			if _, ok := c.counts[logCounterKey{r.Identifier, p.name}]; !ok {
+				c.counts[logCounterKey{r.Identifier, p.name}] = 0
+			}
+		}
+		c.mu.Unlock()
+
+		instance := r.Identifier
+		err := c.follower.follow(instance, activeErrorLog, func(data string) {
+			c.mu.Lock()
+			c.match(instance, data)
+			c.mu.Unlock()
+		})
+		if err != nil {
+			level.Error(c.logger).Log("msg", "Error tailing RDS error log", "instance", r.Identifier, "err", err)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stateFile != "" {
+		if err := saveState(c.stateFile, c.state()); err != nil {
+			level.Error(c.logger).Log("msg", "Error saving log tail state file", "err", err)
+		}
+	}
+}
+
+// logFollower follows one log file per RDS instance through
+// DownloadDBLogFilePortion. It keeps a marker per instance. follow must not
+// be called concurrently, the markers and byte counters can be read while
+// it runs.
+type logFollower struct {
+	client   LogTailGatherer
+	maxBytes int
+
+	mu      sync.Mutex
+	markers map[string]logMarker
+	bytes   map[string]float64
+}
+
+func newLogFollower(client LogTailGatherer, maxBytes int) *logFollower {
+	return &logFollower{
+		client:   client,
+		maxBytes: maxBytes,
+		markers:  map[string]logMarker{},
+		bytes:    map[string]float64{},
+	}
+}
+
+// follow reads the log file picked by selectFile from its marker, following
+// rotations, until the end of the log or the byte budget is reached. Every
+// portion read is passed to handle.
+func (f *logFollower) follow(instance string, selectFile func([]*types.DBLogFile) *types.DBLogFile, handle func(data string)) error {
+	files, err := f.client.GetRDSLogFiles(instance)
+	if err != nil {
+		return err
+	}
+	active := selectFile(files)
+	if active == nil {
+		return nil
+	}
+
+	f.mu.Lock()
+	m, ok := f.markers[instance]
+	f.mu.Unlock()
+	if !ok {
+		// first time the instance is seen: start at the end, the history
+		// of the log is not read
+		p, err := f.client.DownloadRDSLogFilePortion(instance, active.Name, "", 1)
+		if err != nil {
+			return err
+		}
+		f.setMarker(instance, logMarker{File: active.Name, Marker: p.Marker, Size: active.Size})
+		return nil
+	}
+
+	budget := f.maxBytes
+	if m.File != active.Name {
+		// the log rotated to a new file, finish the previous one if it is still there
+		for _, file := range files {
+			if file.Name == m.File {
+				if m, budget, err = f.read(instance, m, budget, handle); err != nil {
+					return err
+				}
+				break
+			}
+		}
+		if budget <= 0 {
+			f.setMarker(instance, m)
+			return nil
+		}
+		m = logMarker{File: active.Name, Marker: "0"}
+	} else if active.Size < m.Size {
+		// the log was truncated or rotated in place
+		m.Marker = "0"
+	}
+
+	m, _, err = f.read(instance, m, budget, handle)
+	m.Size = active.Size
+	f.setMarker(instance, m)
+	return err
+}
+
+func (f *logFollower) setMarker(instance string, m logMarker) {
+	f.mu.Lock()
+	f.markers[instance] = m
+	f.mu.Unlock()
+}
+
+// snapshot returns a copy of the markers and byte counters
+func (f *logFollower) snapshot() (map[string]logMarker, map[string]float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	markers := make(map[string]logMarker, len(f.markers))
+	for k, v := range f.markers {
+		markers[k] = v
+	}
+	bytes := make(map[string]float64, len(f.bytes))
+	for k, v := range f.bytes {
+		bytes[k] = v
+	}
+	return markers, bytes
+}
+
+// read downloads the log file from the marker and passes the data to handle.
+// It returns the updated marker and the remaining budget.
+func (f *logFollower) read(instance string, m logMarker, budget int, handle func(data string)) (logMarker, int, error) {
+	for budget > 0 {
+		p, err := f.client.DownloadRDSLogFilePortion(instance, m.File, m.Marker, 0)
+		if err != nil {
+			return m, budget, err
+		}
+
+		handle(p.Data)
+		budget -= len(p.Data)
+		f.mu.Lock()
+		f.bytes[instance] += float64(len(p.Data))
+		f.mu.Unlock()
+		if p.Marker != "" {
+			m.Marker = p.Marker
+		}
+
+		if !p.AdditionalDataPending {
+			break
+		}
+	}
+	return m, budget, nil
+}
+
+func (c *logTailCollector) match(instance, data string) {
+	for _, line := range strings.Split(data, "\n") {
+		if line == "" {
+			continue
+		}
+		for _, p := range c.patterns {
+			if p.regexp.MatchString(line) {
+				c.counts[logCounterKey{instance, p.name}]++
+			}
+		}
+	}
+}
+
+func (c *logTailCollector) state() logTailState {
+	markers, bytes := c.follower.snapshot()
+	st := logTailState{
+		Markers: markers,
+		Bytes:   bytes,
+	}
+	for k, v := range c.counts {
+		st.Counters = append(st.Counters, logCounter{k, v})
+	}
+	return st
+}
+
+// Describe describes the metrics exported by the log tail collector. It
+// implements prometheus.Collector.
+func (c *logTailCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- logPatternMatches
+	ch <- logTailBytes
+}
+
+// Collect delivers the pattern counters gathered by the log tailer as
+// Prometheus metrics. It implements prometheus.Collector
+func (c *logTailCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, v := range c.counts {
+		ch <- prometheus.MustNewConstMetric(
+			logPatternMatches, prometheus.CounterValue, v, c.region, k.Instance, k.Pattern,
+		)
+	}
+	_, bytes := c.follower.snapshot()
+	for instance, v := range bytes {
+		ch <- prometheus.MustNewConstMetric(
+			logTailBytes, prometheus.CounterValue, v, c.region, instance,
+		)
+	}
+}
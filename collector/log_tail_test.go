@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+func TestActiveErrorLog(t *testing.T) {
+	t0 := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	files := []*types.DBLogFile{
+		{Name: "error/mysql-error-running.log.1", LastWritten: t0.Add(-time.Hour)},
+		{Name: "error/mysql-error-running.log", LastWritten: t0},
+		{Name: "slowquery/mysql-slowquery.log", LastWritten: t0.Add(time.Minute)},
+	}
+
+	if got := activeErrorLog(files); got == nil || got.Name != "error/mysql-error-running.log" {
+		t.Errorf("Wanted error/mysql-error-running.log as active error log, got %v", got)
+	}
+}
+
+func TestLogTailCollector(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logtail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "logtail.json")
+
+	instance := types.DBInstance{Identifier: "rds-dbinstance-1", Engine: "postgres"}
+	t0 := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	patterns := []string{"fatal=FATAL", "deadlock=deadlock detected"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false, instance)
+	awsMock.MockDescribeDBLogFilesPages(t, mockRDS, false,
+		types.DBLogFile{Name: "error/postgresql.log.2020-10-01-12", Size: 4096, LastWritten: t0},
+	)
+	awsMock.MockDownloadDBLogFilePortion(t, mockRDS, false,
+		// the tail of the log when the instance is first seen, not counted
+		types.DBLogFilePortion{Data: "FATAL: old news\n", Marker: "12:100"},
+		types.DBLogFilePortion{Data: "FATAL: password authentication failed\nERROR: deadlock detected\n", Marker: "12:200", AdditionalDataPending: true},
+		types.DBLogFilePortion{Data: "LOG: checkpoint complete\nFATAL: too many connections\n", Marker: "12:300"},
+	)
+
+	c, err := NewLogTailCollector(&RDSClient{client: mockRDS}, "us-east-1", patterns, 1<<20, stateFile, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.poll()
+	c.poll()
+
+	if got := c.counts[logCounterKey{"rds-dbinstance-1", "fatal"}]; got != 2 {
+		t.Errorf("Wanted 2 fatal matches, got %v", got)
+	}
+	if got := c.counts[logCounterKey{"rds-dbinstance-1", "deadlock"}]; got != 1 {
+		t.Errorf("Wanted 1 deadlock match, got %v", got)
+	}
+	if got := c.follower.markers["rds-dbinstance-1"].Marker; got != "12:300" {
+		t.Errorf("Wanted marker 12:300, got %v", got)
+	}
+
+	// a restarted collector continues from the saved marker and counters
+	c, err = NewLogTailCollector(&RDSClient{client: mockRDS}, "us-east-1", patterns, 1<<20, stateFile, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.follower.markers["rds-dbinstance-1"].Marker; got != "12:300" {
+		t.Errorf("Wanted restored marker 12:300, got %v", got)
+	}
+	if got := c.counts[logCounterKey{"rds-dbinstance-1", "fatal"}]; got != 2 {
+		t.Errorf("Wanted 2 restored fatal matches, got %v", got)
+	}
+
+	if _, err := NewLogTailCollector(&RDSClient{client: mockRDS}, "us-east-1", []string{"broken"}, 1<<20, "", log.NewNopLogger()); err == nil {
+		t.Errorf("Wanted an error for a pattern without a name")
+	}
+}
@@ -0,0 +1,127 @@
+package collector
+
+import (
+	"sort"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+// Metrics descriptions
+var (
+	instanceOrderable = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "instance", "orderable"),
+		"Whether the engine, version, class and storage combination of the RDS instance is still orderable in the region",
+		[]string{"region", "instance", "class", "engine", "engine_version", "storage_type"},
+		nil,
+	)
+
+	instanceClassInstances = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "instance_class", "instances"),
+		"Number of RDS instances of the instance class",
+		[]string{"region", "class"},
+		nil,
+	)
+
+	instanceClassNonOrderable = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "instance_class", "non_orderable_instances"),
+		"Number of RDS instances of the instance class whose combination is no longer orderable",
+		[]string{"region", "class"},
+		nil,
+	)
+)
+
+// OrderableGatherer is the interface that implements the methods required to gather orderable instance options
+type OrderableGatherer interface {
+	RDSGatherer
+	GetRDSOrderableDBInstanceOptions(engine, version string) ([]*types.OrderableDBInstanceOption, error)
+}
+
+// NewOrderableClassesCollector returns a collector that checks whether the
+// RDS instances could still be created as they are. Orderable options are
+// cached per engine version for cacheTTL.
+func NewOrderableClassesCollector(client OrderableGatherer, awsRegion string, cacheTTL time.Duration, logger log.Logger) *orderableClassesCollector {
+	return &orderableClassesCollector{
+		client: client,
+		region: awsRegion,
+		cache:  newTTLCache(cacheTTL),
+		logger: logger,
+	}
+}
+
+type orderableClassesCollector struct {
+	client OrderableGatherer
+	region string
+	cache  *ttlCache
+	logger log.Logger
+}
+
+// Describe describes the metrics exported by the orderable classes
+// collector. It implements prometheus.Collector.
+func (c *orderableClassesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- instanceOrderable
+	ch <- instanceClassInstances
+	ch <- instanceClassNonOrderable
+}
+
+// isOrderable returns whether the combination of the instance is one of the orderable options.
+// The license model is only compared when the instance reports one.
+func isOrderable(r *types.DBInstance, os []*types.OrderableDBInstanceOption) bool {
+	for _, o := range os {
+		if o.Class != r.Class || o.StorageType != r.StorageType {
+			continue
+		}
+		if r.LicenseModel != "" && o.LicenseModel != r.LicenseModel {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// Collect checks every RDS instance against the orderable options of its
+// engine version and delivers the result as Prometheus metrics. It
+// implements prometheus.Collector
+func (c *orderableClassesCollector) Collect(ch chan<- prometheus.Metric) {
+	rs, err := c.client.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+
+	instances := map[string]int{}
+	nonOrderable := map[string]int{}
+	for _, r := range rs {
+		v, err := c.cache.get("orderable/"+r.Engine+"/"+r.EngineVersion, func() (interface{}, error) {
+			return c.client.GetRDSOrderableDBInstanceOptions(r.Engine, r.EngineVersion)
+		})
+		if err != nil {
+			level.Error(c.logger).Log("msg", "Error getting RDS orderable instance options", "instance", r.Identifier, "err", err)
+			continue
+		}
+
+		orderable := isOrderable(r, v.([]*types.OrderableDBInstanceOption))
+		instances[r.Class]++
+		if !orderable {
+			nonOrderable[r.Class]++
+		}
+		ch <- prometheus.MustNewConstMetric(
+			instanceOrderable, prometheus.GaugeValue, boolToFloat(orderable),
+			c.region, r.Identifier, r.Class, r.Engine, r.EngineVersion, r.StorageType,
+		)
+	}
+
+	classes := []string{}
+	for class := range instances {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		ch <- prometheus.MustNewConstMetric(instanceClassInstances, prometheus.GaugeValue, float64(instances[class]), c.region, class)
+		ch <- prometheus.MustNewConstMetric(instanceClassNonOrderable, prometheus.GaugeValue, float64(nonOrderable[class]), c.region, class)
+	}
+}
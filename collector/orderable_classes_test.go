@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+func TestOrderableClassesCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false,
+		types.DBInstance{Identifier: "db-1", Class: "db.m5.large", Engine: "mysql", EngineVersion: "5.7.31", StorageType: "gp2"},
+		types.DBInstance{Identifier: "db-2", Class: "db.m3.large", Engine: "mysql", EngineVersion: "5.7.31", StorageType: "gp2"},
+		types.DBInstance{Identifier: "db-3", Class: "db.m5.large", Engine: "mysql", EngineVersion: "5.7.31", StorageType: "standard"},
+	)
+	awsMock.MockDescribeOrderableDBInstanceOptionsPages(t, mockRDS, false,
+		types.OrderableDBInstanceOption{Class: "db.m5.large", Engine: "mysql", EngineVersion: "5.7.31", LicenseModel: "general-public-license", StorageType: "gp2"},
+		types.OrderableDBInstanceOption{Class: "db.m5.large", Engine: "mysql", EngineVersion: "5.7.31", LicenseModel: "general-public-license", StorageType: "io1"},
+	)
+
+	c := NewOrderableClassesCollector(&RDSClient{client: mockRDS}, "us-east-1", time.Hour, log.NewNopLogger())
+
+	want := `
+# HELP aws_rds_instance_class_instances Number of RDS instances of the instance class
+# TYPE aws_rds_instance_class_instances gauge
+aws_rds_instance_class_instances{class="db.m3.large",region="us-east-1"} 1
+aws_rds_instance_class_instances{class="db.m5.large",region="us-east-1"} 2
+# HELP aws_rds_instance_class_non_orderable_instances Number of RDS instances of the instance class whose combination is no longer orderable
+# TYPE aws_rds_instance_class_non_orderable_instances gauge
+aws_rds_instance_class_non_orderable_instances{class="db.m3.large",region="us-east-1"} 1
+aws_rds_instance_class_non_orderable_instances{class="db.m5.large",region="us-east-1"} 1
+# HELP aws_rds_instance_orderable Whether the engine, version, class and storage combination of the RDS instance is still orderable in the region
+# TYPE aws_rds_instance_orderable gauge
+aws_rds_instance_orderable{class="db.m3.large",engine="mysql",engine_version="5.7.31",instance="db-2",region="us-east-1",storage_type="gp2"} 0
+aws_rds_instance_orderable{class="db.m5.large",engine="mysql",engine_version="5.7.31",instance="db-1",region="us-east-1",storage_type="gp2"} 1
+aws_rds_instance_orderable{class="db.m5.large",engine="mysql",engine_version="5.7.31",instance="db-3",region="us-east-1",storage_type="standard"} 0
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
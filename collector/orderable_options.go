@@ -0,0 +1,40 @@
+package collector
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+// GetRDSOrderableDBInstanceOptions will get the orderable options of the
+// engine version from the RDS API. If version is empty, the options of all
+// versions of the engine are returned.
+func (e *RDSClient) GetRDSOrderableDBInstanceOptions(engine, version string) ([]*types.OrderableDBInstanceOption, error) {
+	os := []*types.OrderableDBInstanceOption{}
+	params := &rds.DescribeOrderableDBInstanceOptionsInput{
+		Engine:     aws.String(engine),
+		MaxRecords: aws.Int64(e.apiMaxResults),
+	}
+	if version != "" {
+		params.EngineVersion = aws.String(version)
+	}
+
+	err := e.client.DescribeOrderableDBInstanceOptionsPages(params, func(page *rds.DescribeOrderableDBInstanceOptionsOutput, lastPage bool) bool {
+		for _, o := range page.OrderableDBInstanceOptions {
+			os = append(os, &types.OrderableDBInstanceOption{
+				Class:         aws.StringValue(o.DBInstanceClass),
+				Engine:        aws.StringValue(o.Engine),
+				EngineVersion: aws.StringValue(o.EngineVersion),
+				LicenseModel:  aws.StringValue(o.LicenseModel),
+				StorageType:   aws.StringValue(o.StorageType),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return os, nil
+}
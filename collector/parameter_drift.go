@@ -0,0 +1,167 @@
+package collector
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const parameterSourceUser = "user"
+
+// Metrics descriptions
+var (
+	parameterGroupModified = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "parameter_group", "modified_parameters"),
+		"Number of parameters of the DB parameter group set to a value other than the engine default",
+		[]string{"region", "parameter_group", "family"},
+		nil,
+	)
+
+	parameterGroupModifiedInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "parameter_group", "modified_parameter_info"),
+		"Parameter of the DB parameter group set to a value other than the engine default",
+		[]string{"region", "parameter_group", "parameter", "default_value", "value"},
+		nil,
+	)
+)
+
+type parameterGroupDrift struct {
+	ParameterGroup string           `json:"parameter_group"`
+	Family         string           `json:"family"`
+	Parameters     []parameterDrift `json:"parameters"`
+}
+
+type parameterDrift struct {
+	Name    string `json:"name"`
+	Default string `json:"default_value"`
+	Value   string `json:"value"`
+}
+
+// NewParameterDriftCollector returns a collector that compares the user set
+// parameters of the DB parameter groups in use with the engine defaults.
+// Parameter groups and engine defaults are cached for cacheTTL.
+func NewParameterDriftCollector(client ParameterGatherer, awsRegion string, cacheTTL time.Duration, logger log.Logger) *parameterDriftCollector {
+	return &parameterDriftCollector{
+		client: client,
+		region: awsRegion,
+		cache:  newTTLCache(cacheTTL),
+		logger: logger,
+	}
+}
+
+type parameterDriftCollector struct {
+	client ParameterGatherer
+	region string
+	cache  *ttlCache
+	logger log.Logger
+}
+
+// drift returns the modified parameters of every DB parameter group in use
+func (c *parameterDriftCollector) drift() ([]*parameterGroupDrift, error) {
+	rs, err := c.client.GetRDSInstances()
+	if err != nil {
+		return nil, err
+	}
+	inUse := map[string]bool{}
+	for _, r := range rs {
+		for _, pg := range r.ParameterGroups {
+			inUse[pg] = true
+		}
+	}
+
+	v, err := c.cache.get("groups", func() (interface{}, error) {
+		return c.client.GetRDSParameterGroups()
+	})
+	if err != nil {
+		return nil, err
+	}
+	pgs := v.([]*types.DBParameterGroup)
+
+	drift := []*parameterGroupDrift{}
+	for _, pg := range pgs {
+		if !inUse[pg.Name] {
+			continue
+		}
+
+		v, err := c.cache.get("user/"+pg.Name, func() (interface{}, error) {
+			return c.client.GetRDSParameters(pg.Name, parameterSourceUser)
+		})
+		if err != nil {
+			return nil, err
+		}
+		userParams := v.([]*types.DBParameter)
+
+		family := pg.Family
+		v, err = c.cache.get("defaults/"+family, func() (interface{}, error) {
+			return c.client.GetRDSEngineDefaultParameters(family)
+		})
+		if err != nil {
+			return nil, err
+		}
+		defaults := map[string]string{}
+		for _, p := range v.([]*types.DBParameter) {
+			defaults[p.Name] = p.Value
+		}
+
+		d := &parameterGroupDrift{ParameterGroup: pg.Name, Family: pg.Family, Parameters: []parameterDrift{}}
+		for _, p := range userParams {
+			if def, ok := defaults[p.Name]; ok && def == p.Value {
+				continue
+			}
+			d.Parameters = append(d.Parameters, parameterDrift{Name: p.Name, Default: defaults[p.Name], Value: p.Value})
+		}
+		sort.Slice(d.Parameters, func(i, j int) bool { return d.Parameters[i].Name < d.Parameters[j].Name })
+		drift = append(drift, d)
+	}
+
+	sort.Slice(drift, func(i, j int) bool { return drift[i].ParameterGroup < drift[j].ParameterGroup })
+	return drift, nil
+}
+
+// Describe describes the metrics exported by the parameter drift collector.
+// It implements prometheus.Collector.
+func (c *parameterDriftCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- parameterGroupModified
+	ch <- parameterGroupModifiedInfo
+}
+
+// Collect compares the parameter groups in use with the engine defaults and
+// delivers the differences as Prometheus metrics. It implements prometheus.Collector
+func (c *parameterDriftCollector) Collect(ch chan<- prometheus.Metric) {
+	drift, err := c.drift()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS parameter drift", "err", err)
+		return
+	}
+
+	for _, d := range drift {
+		ch <- prometheus.MustNewConstMetric(
+			parameterGroupModified, prometheus.GaugeValue, float64(len(d.Parameters)), c.region, d.ParameterGroup, d.Family,
+		)
+		for _, p := range d.Parameters {
+			ch <- prometheus.MustNewConstMetric(
+				parameterGroupModifiedInfo, prometheus.GaugeValue, 1, c.region, d.ParameterGroup, p.Name, p.Default, p.Value,
+			)
+		}
+	}
+}
+
+// ServeHTTP writes the parameter drift of the parameter groups in use as JSON
+func (c *parameterDriftCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	drift, err := c.drift()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS parameter drift", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(drift)
+}
@@ -0,0 +1,68 @@
+package collector
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+func TestParameterDrift(t *testing.T) {
+	instance := types.DBInstance{Identifier: "rds-dbinstance-1", Engine: "postgres", ParameterGroups: []string{"custom-pg12"}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false, instance)
+	awsMock.MockDescribeDBParameterGroupsPages(t, mockRDS, false,
+		types.DBParameterGroup{Name: "custom-pg12", Family: "postgres12"},
+		types.DBParameterGroup{Name: "unused", Family: "postgres12"},
+	)
+	awsMock.MockDescribeDBParametersPages(t, mockRDS, false,
+		types.DBParameter{Name: "work_mem", Value: "65536", Source: "user"},
+		types.DBParameter{Name: "log_statement", Value: "all", Source: "user"},
+		types.DBParameter{Name: "autovacuum", Value: "1", Source: "user"},
+		types.DBParameter{Name: "shared_buffers", Value: "{DBInstanceClassMemory/32768}", Source: "system"},
+	)
+	awsMock.MockDescribeEngineDefaultParametersPages(t, mockRDS, false,
+		types.DBParameter{Name: "work_mem", Value: "4096", Source: "engine-default"},
+		types.DBParameter{Name: "autovacuum", Value: "1", Source: "engine-default"},
+	)
+
+	c := NewParameterDriftCollector(&RDSClient{client: mockRDS}, "us-east-1", time.Hour, log.NewNopLogger())
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/parameters/drift", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Wanted status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	drift := []*parameterGroupDrift{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &drift); err != nil {
+		t.Fatal(err)
+	}
+	if len(drift) != 1 || drift[0].ParameterGroup != "custom-pg12" {
+		t.Fatalf("Wanted drift for custom-pg12 only, got %+v", drift)
+	}
+
+	want := []parameterDrift{
+		{Name: "log_statement", Default: "", Value: "all"},
+		{Name: "work_mem", Default: "4096", Value: "65536"},
+	}
+	if len(drift[0].Parameters) != len(want) {
+		t.Fatalf("Wanted modified parameters %+v, got %+v", want, drift[0].Parameters)
+	}
+	for i, p := range drift[0].Parameters {
+		if p != want[i] {
+			t.Errorf("Wanted modified parameter %+v, got %+v", want[i], p)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package collector
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const gibibyte = 1024 * 1024 * 1024
+
+// Metrics descriptions
+var (
+	parameterValue = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "parameter", "value"),
+		"Numeric value of the DB parameter for the RDS instance, in the unit of the parameter",
+		[]string{"region", "instance", "parameter_group", "parameter"},
+		nil,
+	)
+
+	parameterInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "parameter", "info"),
+		"Value of the DB parameter for the RDS instance that could not be resolved to a number",
+		[]string{"region", "instance", "parameter_group", "parameter", "value"},
+		nil,
+	)
+
+	instanceClassMemory = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "instance_class", "memory_bytes"),
+		"Memory of the instance class of the RDS instance",
+		[]string{"region", "instance", "class"},
+		nil,
+	)
+)
+
+// formulaVariables are the values DB parameter formulas are evaluated against
+type formulaVariables struct {
+	DBInstanceClassMemory float64
+	DBInstanceVCPU        float64
+	AllocatedStorage      float64
+}
+
+// evalParameter evaluates a DB parameter value, either a plain number or a
+// formula like {DBInstanceClassMemory*3/4} or GREATEST({DBInstanceClassMemory/9531392},5000).
+// Like RDS, the result is truncated to an integer.
+func evalParameter(value string, vars formulaVariables) (float64, error) {
+	p := &formulaParser{input: value, vars: vars}
+	v, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return 0, fmt.Errorf("unexpected %q at %d", p.input[p.pos:], p.pos)
+	}
+	return math.Trunc(v), nil
+}
+
+// formulaParser is a recursive descent parser for DB parameter formulas
+type formulaParser struct {
+	input string
+	pos   int
+	vars  formulaVariables
+}
+
+func (p *formulaParser) skipSpace() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *formulaParser) peek() byte {
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+func (p *formulaParser) expect(c byte) error {
+	if p.peek() != c {
+		return fmt.Errorf("expected %q at %d in %q", c, p.pos, p.input)
+	}
+	p.pos++
+	return nil
+}
+
+// expr := term (('+' | '-') term)*
+func (p *formulaParser) expr() (float64, error) {
+	v, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return v, nil
+		}
+		p.pos++
+		r, err := p.term()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			v += r
+		} else {
+			v -= r
+		}
+	}
+}
+
+// term := factor (('*' | '/') factor)*
+func (p *formulaParser) term() (float64, error) {
+	v, err := p.factor()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' {
+			return v, nil
+		}
+		p.pos++
+		r, err := p.factor()
+		if err != nil {
+			return 0, err
+		}
+		if op == '*' {
+			v *= r
+		} else {
+			if r == 0 {
+				return 0, fmt.Errorf("division by zero in %q", p.input)
+			}
+			v /= r
+		}
+	}
+}
+
+// factor := number | variable | function '(' expr (',' expr)* ')' | '(' expr ')' | '{' expr '}' | '-' factor
+func (p *formulaParser) factor() (float64, error) {
+	c := p.peek()
+	switch {
+	case c == '-':
+		p.pos++
+		v, err := p.factor()
+		return -v, err
+	case c == '(' || c == '{':
+		p.pos++
+		v, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		closing := byte(')')
+		if c == '{' {
+			closing = '}'
+		}
+		return v, p.expect(closing)
+	case c >= '0' && c <= '9' || c == '.':
+		start := p.pos
+		for p.pos < len(p.input) && (p.input[p.pos] >= '0' && p.input[p.pos] <= '9' || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		return strconv.ParseFloat(p.input[start:p.pos], 64)
+	case unicode.IsLetter(rune(c)):
+		start := p.pos
+		for p.pos < len(p.input) && (unicode.IsLetter(rune(p.input[p.pos])) || unicode.IsDigit(rune(p.input[p.pos]))) {
+			p.pos++
+		}
+		name := p.input[start:p.pos]
+		if p.peek() == '(' {
+			return p.function(name)
+		}
+		return p.variable(name)
+	}
+	return 0, fmt.Errorf("unexpected %q at %d in %q", c, p.pos, p.input)
+}
+
+func (p *formulaParser) variable(name string) (float64, error) {
+	switch strings.ToLower(name) {
+	case "dbinstanceclassmemory", "dbinstancevcpu":
+		if p.vars.DBInstanceClassMemory == 0 {
+			return 0, fmt.Errorf("unknown instance class for %q", name)
+		}
+		if strings.EqualFold(name, "DBInstanceVCPU") {
+			return p.vars.DBInstanceVCPU, nil
+		}
+		return p.vars.DBInstanceClassMemory, nil
+	case "allocatedstorage":
+		return p.vars.AllocatedStorage, nil
+	}
+	return 0, fmt.Errorf("unknown variable %q", name)
+}
+
+func (p *formulaParser) function(name string) (float64, error) {
+	p.pos++ // '('
+	args := []float64{}
+	for {
+		v, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, v)
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+	}
+	if err := p.expect(')'); err != nil {
+		return 0, err
+	}
+
+	v := args[0]
+	switch strings.ToUpper(name) {
+	case "GREATEST":
+		for _, a := range args[1:] {
+			v = math.Max(v, a)
+		}
+	case "LEAST":
+		for _, a := range args[1:] {
+			v = math.Min(v, a)
+		}
+	case "SUM":
+		for _, a := range args[1:] {
+			v += a
+		}
+	default:
+		return 0, fmt.Errorf("unknown function %q", name)
+	}
+	return v, nil
+}
+
+// NewParameterValuesCollector returns a collector that exports the named DB
+// parameters of every RDS instance, resolved from its DB parameter group.
+// Parameter groups are cached for cacheTTL.
+func NewParameterValuesCollector(client ParameterGatherer, awsRegion string, names []string, cacheTTL time.Duration, logger log.Logger) *parameterValuesCollector {
+	return &parameterValuesCollector{
+		client: client,
+		region: awsRegion,
+		names:  names,
+		cache:  newTTLCache(cacheTTL),
+		logger: logger,
+	}
+}
+
+type parameterValuesCollector struct {
+	client ParameterGatherer
+	region string
+	names  []string
+	cache  *ttlCache
+	logger log.Logger
+}
+
+// Describe describes the metrics exported by the parameter values collector.
+// It implements prometheus.Collector.
+func (c *parameterValuesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- parameterValue
+	ch <- parameterInfo
+	ch <- instanceClassMemory
+}
+
+// Collect resolves the configured parameters of every RDS instance and
+// delivers them as Prometheus metrics. It implements prometheus.Collector
+func (c *parameterValuesCollector) Collect(ch chan<- prometheus.Metric) {
+	rs, err := c.client.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+
+	for _, r := range rs {
+		ic, known := lookupInstanceClass(r.Class)
+		if known {
+			ch <- prometheus.MustNewConstMetric(
+				instanceClassMemory, prometheus.GaugeValue, ic.MemoryGiB*gibibyte, c.region, r.Identifier, r.Class,
+			)
+		}
+
+		if len(r.ParameterGroups) == 0 {
+			continue
+		}
+		group := r.ParameterGroups[0]
+
+		v, err := c.cache.get(group, func() (interface{}, error) {
+			return c.client.GetRDSParameters(group, "")
+		})
+		if err != nil {
+			level.Error(c.logger).Log("msg", "Error getting RDS parameters", "parameter_group", group, "err", err)
+			continue
+		}
+		values := map[string]string{}
+		for _, p := range v.([]*types.DBParameter) {
+			values[p.Name] = p.Value
+		}
+
+		vars := formulaVariables{AllocatedStorage: r.AllocatedStorage}
+		if known {
+			vars.DBInstanceClassMemory = ic.MemoryGiB * gibibyte
+			vars.DBInstanceVCPU = ic.VCPU
+		}
+
+		for _, name := range c.names {
+			value := values[name]
+			n, err := evalParameter(value, vars)
+			if err != nil {
+				ch <- prometheus.MustNewConstMetric(
+					parameterInfo, prometheus.GaugeValue, 1, c.region, r.Identifier, group, name, value,
+				)
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(
+				parameterValue, prometheus.GaugeValue, n, c.region, r.Identifier, group, name,
+			)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+func TestEvalParameter(t *testing.T) {
+	vars := formulaVariables{DBInstanceClassMemory: 16 * gibibyte, DBInstanceVCPU: 2}
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{"100", 100, false},
+		{"{DBInstanceClassMemory*3/4}", 12 * gibibyte, false},
+		{"{DBInstanceClassMemory/32768}", 524288, false},
+		{"LEAST({DBInstanceClassMemory/9531392},5000)", 1802, false},
+		{"GREATEST({DBInstanceVCPU/2},1)", 1, false},
+		{"SUM({DBInstanceVCPU},-1)", 1, false},
+		{"all", 0, true},
+		{"", 0, true},
+		{"{DBInstanceClassMemory*3/4", 0, true},
+	}
+
+	for _, test := range tests {
+		got, err := evalParameter(test.value, vars)
+		if (err != nil) != test.wantErr {
+			t.Errorf("evalParameter(%q): wanted error %v, got %v", test.value, test.wantErr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("evalParameter(%q): wanted %v, got %v", test.value, test.want, got)
+		}
+	}
+
+	if _, err := evalParameter("{DBInstanceClassMemory*3/4}", formulaVariables{}); err == nil {
+		t.Errorf("Wanted an error for a formula of an unknown instance class")
+	}
+}
+
+func TestParameterValuesCollector(t *testing.T) {
+	instance := types.DBInstance{Identifier: "rds-dbinstance-1", Class: "db.r5.large", Engine: "postgres", ParameterGroups: []string{"custom-pg12"}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false, instance)
+	awsMock.MockDescribeDBParametersPages(t, mockRDS, false,
+		types.DBParameter{Name: "max_connections", Value: "LEAST({DBInstanceClassMemory/9531392},5000)", Source: "system"},
+		types.DBParameter{Name: "shared_buffers", Value: "{DBInstanceClassMemory/32768}", Source: "system"},
+		types.DBParameter{Name: "log_statement", Value: "all", Source: "user"},
+	)
+
+	c := NewParameterValuesCollector(&RDSClient{client: mockRDS}, "us-east-1", []string{"max_connections", "shared_buffers", "log_statement"}, time.Hour, log.NewNopLogger())
+
+	want := `
+# HELP aws_rds_parameter_info Value of the DB parameter for the RDS instance that could not be resolved to a number
+# TYPE aws_rds_parameter_info gauge
+aws_rds_parameter_info{instance="rds-dbinstance-1",parameter="log_statement",parameter_group="custom-pg12",region="us-east-1",value="all"} 1
+# HELP aws_rds_parameter_value Numeric value of the DB parameter for the RDS instance, in the unit of the parameter
+# TYPE aws_rds_parameter_value gauge
+aws_rds_parameter_value{instance="rds-dbinstance-1",parameter="max_connections",parameter_group="custom-pg12",region="us-east-1"} 1802
+aws_rds_parameter_value{instance="rds-dbinstance-1",parameter="shared_buffers",parameter_group="custom-pg12",region="us-east-1"} 524288
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "aws_rds_parameter_info", "aws_rds_parameter_value"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,96 @@
+package collector
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+// ParameterGatherer is the interface that implements the methods required to gather DB parameter data
+type ParameterGatherer interface {
+	RDSGatherer
+	GetRDSParameterGroups() ([]*types.DBParameterGroup, error)
+	GetRDSParameters(group, source string) ([]*types.DBParameter, error)
+	GetRDSEngineDefaultParameters(family string) ([]*types.DBParameter, error)
+}
+
+// GetRDSParameterGroups will get the DB parameter groups from the RDS API
+func (e *RDSClient) GetRDSParameterGroups() ([]*types.DBParameterGroup, error) {
+	pgs := []*types.DBParameterGroup{}
+	params := &rds.DescribeDBParameterGroupsInput{
+		MaxRecords: aws.Int64(e.apiMaxResults),
+	}
+
+	err := e.client.DescribeDBParameterGroupsPages(params, func(page *rds.DescribeDBParameterGroupsOutput, lastPage bool) bool {
+		for _, pg := range page.DBParameterGroups {
+			pgs = append(pgs, &types.DBParameterGroup{
+				Name:   aws.StringValue(pg.DBParameterGroupName),
+				Family: aws.StringValue(pg.DBParameterGroupFamily),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pgs, nil
+}
+
+// GetRDSParameters will get the parameters of the DB parameter group from
+// the RDS API. If source is not empty, only parameters of that source are returned.
+func (e *RDSClient) GetRDSParameters(group, source string) ([]*types.DBParameter, error) {
+	ps := []*types.DBParameter{}
+	params := &rds.DescribeDBParametersInput{
+		DBParameterGroupName: aws.String(group),
+		MaxRecords:           aws.Int64(e.apiMaxResults),
+	}
+	if source != "" {
+		params.Source = aws.String(source)
+	}
+
+	err := e.client.DescribeDBParametersPages(params, func(page *rds.DescribeDBParametersOutput, lastPage bool) bool {
+		ps = append(ps, toDBParameters(page.Parameters)...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+// GetRDSEngineDefaultParameters will get the engine default parameters of the family from the RDS API
+func (e *RDSClient) GetRDSEngineDefaultParameters(family string) ([]*types.DBParameter, error) {
+	ps := []*types.DBParameter{}
+	params := &rds.DescribeEngineDefaultParametersInput{
+		DBParameterGroupFamily: aws.String(family),
+		MaxRecords:             aws.Int64(e.apiMaxResults),
+	}
+
+	err := e.client.DescribeEngineDefaultParametersPages(params, func(page *rds.DescribeEngineDefaultParametersOutput, lastPage bool) bool {
+		if page.EngineDefaults != nil {
+			ps = append(ps, toDBParameters(page.EngineDefaults.Parameters)...)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+func toDBParameters(parameters []*rds.Parameter) []*types.DBParameter {
+	ps := []*types.DBParameter{}
+	for _, p := range parameters {
+		ps = append(ps, &types.DBParameter{
+			Name:     aws.StringValue(p.ParameterName),
+			Value:    aws.StringValue(p.ParameterValue),
+			Source:   aws.StringValue(p.Source),
+			DataType: aws.StringValue(p.DataType),
+		})
+	}
+	return ps
+}
@@ -0,0 +1,274 @@
+package collector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/pi"
+	"github.com/aws/aws-sdk-go/service/pi/piiface"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const (
+	piSubsystem = "performance_insights"
+
+	piDBLoad = "db.load.avg"
+
+	piGroupWaitEventType = "db.wait_event_type"
+	piGroupWaitEvent     = "db.wait_event"
+	piGroupSQLTokenized  = "db.sql_tokenized"
+
+	piDimensionWaitEventType = "db.wait_event_type.name"
+	piDimensionWaitEvent     = "db.wait_event.name"
+	piDimensionWaitEventKind = "db.wait_event.type"
+	piDimensionSQLID         = "db.sql_tokenized.id"
+	piDimensionSQLStatement  = "db.sql_tokenized.statement"
+
+	// piMaxGroupLimit is the highest limit of a dimension group the API accepts
+	piMaxGroupLimit = 25
+
+	// piStatementLength caps the length of the statement label
+	piStatementLength = 200
+)
+
+// Metrics descriptions
+var (
+	piDBLoadAvg = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, piSubsystem, "db_load_average"),
+		"Average number of active sessions of the RDS instance",
+		labels,
+		nil,
+	)
+
+	piDBLoadByWaitEventType = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, piSubsystem, "db_load_by_wait_event_type_average"),
+		"Average number of active sessions of the RDS instance by wait event type",
+		[]string{"region", "instance", "wait_event_type"},
+		nil,
+	)
+
+	piDBLoadByWaitEvent = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, piSubsystem, "db_load_by_wait_event_average"),
+		"Average number of active sessions of the RDS instance for the top wait events",
+		[]string{"region", "instance", "wait_event", "wait_event_type"},
+		nil,
+	)
+
+	piDBLoadBySQL = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, piSubsystem, "db_load_by_sql_average"),
+		"Average number of active sessions of the RDS instance for the top SQL digests",
+		[]string{"region", "instance", "sql_id", "statement"},
+		nil,
+	)
+)
+
+// PIClient is a wrapper for AWS Performance Insights client that implements helpers to get DB load
+type PIClient struct {
+	client piiface.PIAPI
+}
+
+// PerformanceInsightsGatherer is the interface that implements the methods required to gather Performance Insights data
+type PerformanceInsightsGatherer interface {
+	GetResourceMetrics(resourceID, metric string, groups []string, limit int64, start, end time.Time, period time.Duration) ([]*types.ResourceMetric, error)
+}
+
+// NewPIClient will return an initialized PIClient
+func NewPIClient(awsRegion string) (*PIClient, error) {
+	// Create AWS session
+	s := session.New(&aws.Config{Region: aws.String(awsRegion)})
+	if s == nil {
+		return nil, fmt.Errorf("error creating aws session")
+	}
+
+	return &PIClient{
+		client: pi.New(s),
+	}, nil
+}
+
+// GetResourceMetrics will get the metric of the instance from the
+// Performance Insights API, once in total and once broken down by each of
+// the groups. An empty group asks for the metric in total.
+func (e *PIClient) GetResourceMetrics(resourceID, metric string, groups []string, limit int64, start, end time.Time, period time.Duration) ([]*types.ResourceMetric, error) {
+	params := &pi.GetResourceMetricsInput{
+		ServiceType:     aws.String(pi.ServiceTypeRds),
+		Identifier:      aws.String(resourceID),
+		StartTime:       aws.Time(start),
+		EndTime:         aws.Time(end),
+		PeriodInSeconds: aws.Int64(int64(period.Seconds())),
+	}
+	for _, g := range groups {
+		q := &pi.MetricQuery{Metric: aws.String(metric)}
+		if g != "" {
+			q.GroupBy = &pi.DimensionGroup{Group: aws.String(g), Limit: aws.Int64(limit)}
+		}
+		params.MetricQueries = append(params.MetricQueries, q)
+	}
+
+	ms := []*types.ResourceMetric{}
+	for {
+		resp, err := e.client.GetResourceMetrics(params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range resp.MetricList {
+			r := &types.ResourceMetric{Dimensions: map[string]string{}}
+			if m.Key != nil {
+				r.Metric = aws.StringValue(m.Key.Metric)
+				for k, v := range m.Key.Dimensions {
+					r.Dimensions[k] = aws.StringValue(v)
+				}
+			}
+			for _, d := range m.DataPoints {
+				if d.Timestamp == nil || d.Value == nil {
+					continue
+				}
+				r.Timestamps = append(r.Timestamps, aws.TimeValue(d.Timestamp))
+				r.Values = append(r.Values, aws.Float64Value(d.Value))
+			}
+			ms = append(ms, r)
+		}
+
+		if resp.NextToken == nil {
+			break
+		}
+		params.NextToken = resp.NextToken
+	}
+
+	return ms, nil
+}
+
+// newestValue returns the value of the newest datapoint of the metric
+func newestValue(m *types.ResourceMetric) (float64, bool) {
+	if len(m.Values) == 0 {
+		return 0, false
+	}
+	newest := 0
+	for i := range m.Timestamps {
+		if m.Timestamps[i].After(m.Timestamps[newest]) {
+			newest = i
+		}
+	}
+	return m.Values[newest], true
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	rs := []rune(s)
+	if len(rs) <= n {
+		return s
+	}
+	return string(rs[:n])
+}
+
+// piPeriods are the periods GetResourceMetrics accepts
+var piPeriods = []time.Duration{time.Second, time.Minute, 5 * time.Minute, time.Hour, 24 * time.Hour}
+
+// NewPerformanceInsightsCollector returns a collector for the DB load of the
+// RDS instances with Performance Insights enabled. top caps the number of
+// wait events and SQL digests exported per instance. The load is averaged
+// over period, which must be one of piPeriods, and read delay before now.
+func NewPerformanceInsightsCollector(rdsClient RDSGatherer, client PerformanceInsightsGatherer, awsRegion string, top int, period, delay time.Duration, logger log.Logger) (*performanceInsightsCollector, error) {
+	valid := false
+	for _, p := range piPeriods {
+		valid = valid || period == p
+	}
+	if !valid {
+		return nil, fmt.Errorf("invalid Performance Insights period %v, must be one of %v", period, piPeriods)
+	}
+
+	limit := int64(top)
+	if limit > piMaxGroupLimit {
+		limit = piMaxGroupLimit
+	}
+	if limit < 1 {
+		limit = 1
+	}
+
+	return &performanceInsightsCollector{
+		rdsClient: rdsClient,
+		client:    client,
+		region:    awsRegion,
+		limit:     limit,
+		period:    period,
+		delay:     delay,
+		logger:    logger,
+		now:       time.Now,
+	}, nil
+}
+
+type performanceInsightsCollector struct {
+	rdsClient RDSGatherer
+	client    PerformanceInsightsGatherer
+	region    string
+	limit     int64
+	period    time.Duration
+	delay     time.Duration
+	logger    log.Logger
+	now       func() time.Time
+}
+
+// Describe describes the metrics exported by the Performance Insights
+// collector. It implements prometheus.Collector.
+func (c *performanceInsightsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- piDBLoadAvg
+	ch <- piDBLoadByWaitEventType
+	ch <- piDBLoadByWaitEvent
+	ch <- piDBLoadBySQL
+}
+
+// Collect fetches the DB load of every RDS instance with Performance
+// Insights enabled and delivers it as Prometheus metrics. It implements
+// prometheus.Collector
+func (c *performanceInsightsCollector) Collect(ch chan<- prometheus.Metric) {
+	rs, err := c.rdsClient.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+
+	// the last complete period delay before now, Performance Insights
+	// publishes the load with a lag
+	end := c.now().Add(-c.delay).Truncate(c.period)
+	start := end.Add(-c.period)
+	groups := []string{"", piGroupWaitEventType, piGroupWaitEvent, piGroupSQLTokenized}
+
+	for _, r := range rs {
+		if !r.PerformanceInsights || r.ResourceID == "" {
+			continue
+		}
+
+		ms, err := c.client.GetResourceMetrics(r.ResourceID, piDBLoad, groups, c.limit, start, end, c.period)
+		if err != nil {
+			level.Error(c.logger).Log("msg", "Error getting Performance Insights metrics", "instance", r.Identifier, "err", err)
+			continue
+		}
+
+		for _, m := range ms {
+			v, ok := newestValue(m)
+			if !ok {
+				continue
+			}
+			d := m.Dimensions
+			switch {
+			case len(d) == 0:
+				ch <- prometheus.MustNewConstMetric(piDBLoadAvg, prometheus.GaugeValue, v, c.region, r.Identifier)
+			case d[piDimensionWaitEventType] != "":
+				ch <- prometheus.MustNewConstMetric(piDBLoadByWaitEventType, prometheus.GaugeValue, v, c.region, r.Identifier, d[piDimensionWaitEventType])
+			case d[piDimensionWaitEvent] != "":
+				ch <- prometheus.MustNewConstMetric(piDBLoadByWaitEvent, prometheus.GaugeValue, v, c.region, r.Identifier, d[piDimensionWaitEvent], d[piDimensionWaitEventKind])
+			case d[piDimensionSQLID] != "":
+				ch <- prometheus.MustNewConstMetric(
+					piDBLoadBySQL, prometheus.GaugeValue, v,
+					c.region, r.Identifier, d[piDimensionSQLID], truncate(d[piDimensionSQLStatement], piStatementLength),
+				)
+			}
+		}
+	}
+}
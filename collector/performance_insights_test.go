@@ -0,0 +1,117 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/pi"
+	"github.com/aws/aws-sdk-go/service/pi/piiface"
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+// fakePIAPI returns the metrics of a resource split in two pages
+type fakePIAPI struct {
+	piiface.PIAPI
+	metrics map[string][]*pi.MetricKeyDataPoints
+	inputs  []*pi.GetResourceMetricsInput
+}
+
+func (f *fakePIAPI) GetResourceMetrics(input *pi.GetResourceMetricsInput) (*pi.GetResourceMetricsOutput, error) {
+	f.inputs = append(f.inputs, input)
+	ms := f.metrics[aws.StringValue(input.Identifier)]
+	if input.NextToken == nil {
+		return &pi.GetResourceMetricsOutput{MetricList: ms[:1], NextToken: aws.String("page-2")}, nil
+	}
+	return &pi.GetResourceMetricsOutput{MetricList: ms[1:]}, nil
+}
+
+func piMetric(value float64, dimensions ...string) *pi.MetricKeyDataPoints {
+	key := &pi.ResponseResourceMetricKey{Metric: aws.String(piDBLoad)}
+	if len(dimensions) > 0 {
+		key.Dimensions = map[string]*string{}
+		for i := 0; i+1 < len(dimensions); i += 2 {
+			key.Dimensions[dimensions[i]] = aws.String(dimensions[i+1])
+		}
+	}
+	now := time.Now()
+	return &pi.MetricKeyDataPoints{
+		Key: key,
+		DataPoints: []*pi.DataPoint{
+			{Timestamp: aws.Time(now.Add(-time.Minute)), Value: aws.Float64(value / 2)},
+			{Timestamp: aws.Time(now), Value: aws.Float64(value)},
+			{Timestamp: aws.Time(now.Add(time.Minute))},
+		},
+	}
+}
+
+func TestPerformanceInsightsCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false,
+		types.DBInstance{Identifier: "db-1", ResourceID: "db-ABCDEFGHIJKL", PerformanceInsights: true},
+		types.DBInstance{Identifier: "db-2", ResourceID: "db-MNOPQRSTUVWX"},
+	)
+
+	api := &fakePIAPI{metrics: map[string][]*pi.MetricKeyDataPoints{
+		"db-ABCDEFGHIJKL": {
+			piMetric(3),
+			piMetric(2, piDimensionWaitEventType, "CPU"),
+			piMetric(1, piDimensionWaitEventType, "IO"),
+			piMetric(0.75, piDimensionWaitEvent, "io/table/sql/handler", piDimensionWaitEventKind, "IO"),
+			piMetric(1.5, piDimensionSQLID, "ABC123", piDimensionSQLStatement, "SELECT * FROM orders WHERE id = ?"),
+		},
+	}}
+
+	c, err := NewPerformanceInsightsCollector(&RDSClient{client: mockRDS}, &PIClient{client: api}, "us-east-1", 50, time.Minute, time.Minute, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("Shouldn't return an error, but it did: %v", err)
+	}
+	c.now = func() time.Time { return time.Date(2020, 10, 20, 12, 0, 30, 0, time.UTC) }
+
+	want := `
+# HELP aws_rds_performance_insights_db_load_average Average number of active sessions of the RDS instance
+# TYPE aws_rds_performance_insights_db_load_average gauge
+aws_rds_performance_insights_db_load_average{instance="db-1",region="us-east-1"} 3
+# HELP aws_rds_performance_insights_db_load_by_sql_average Average number of active sessions of the RDS instance for the top SQL digests
+# TYPE aws_rds_performance_insights_db_load_by_sql_average gauge
+aws_rds_performance_insights_db_load_by_sql_average{instance="db-1",region="us-east-1",sql_id="ABC123",statement="SELECT * FROM orders WHERE id = ?"} 1.5
+# HELP aws_rds_performance_insights_db_load_by_wait_event_average Average number of active sessions of the RDS instance for the top wait events
+# TYPE aws_rds_performance_insights_db_load_by_wait_event_average gauge
+aws_rds_performance_insights_db_load_by_wait_event_average{instance="db-1",region="us-east-1",wait_event="io/table/sql/handler",wait_event_type="IO"} 0.75
+# HELP aws_rds_performance_insights_db_load_by_wait_event_type_average Average number of active sessions of the RDS instance by wait event type
+# TYPE aws_rds_performance_insights_db_load_by_wait_event_type_average gauge
+aws_rds_performance_insights_db_load_by_wait_event_type_average{instance="db-1",region="us-east-1",wait_event_type="CPU"} 2
+aws_rds_performance_insights_db_load_by_wait_event_type_average{instance="db-1",region="us-east-1",wait_event_type="IO"} 1
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+
+	// db-2 has Performance Insights disabled, db-1 takes two pages
+	if len(api.inputs) != 2 {
+		t.Fatalf("Wanted %d GetResourceMetrics requests, got %d", 2, len(api.inputs))
+	}
+	for _, q := range api.inputs[0].MetricQueries {
+		if q.GroupBy != nil && aws.Int64Value(q.GroupBy.Limit) != piMaxGroupLimit {
+			t.Errorf("Wanted the group limit capped at %d, got %d", piMaxGroupLimit, aws.Int64Value(q.GroupBy.Limit))
+		}
+	}
+	if end := aws.TimeValue(api.inputs[0].EndTime); !end.Equal(time.Date(2020, 10, 20, 11, 59, 0, 0, time.UTC)) {
+		t.Errorf("Wanted the window to end a minute before the current minute, got %v", end)
+	}
+}
+
+func TestPerformanceInsightsInvalidPeriod(t *testing.T) {
+	if _, err := NewPerformanceInsightsCollector(nil, nil, "us-east-1", 10, 2*time.Minute, time.Minute, log.NewNopLogger()); err == nil {
+		t.Error("Should return an error for a period of 2m, but it didn't")
+	}
+}
@@ -0,0 +1,387 @@
+package collector
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// hoursPerMonth is the number of hours AWS bills a month of storage for
+	hoursPerMonth = 730
+
+	deploymentSingleAZ = "single-az"
+	deploymentMultiAZ  = "multi-az"
+
+	licenseIncluded       = "license-included"
+	licenseBringYourOwn   = "bring-your-own-license"
+	licenseNotRequired    = ""
+	provisionedIopsVolume = "io1"
+)
+
+// offerRegions maps the location names of the price list offer files
+// without a regionCode attribute to their region
+var offerRegions = map[string]string{
+	"US East (N. Virginia)":      "us-east-1",
+	"US East (Ohio)":             "us-east-2",
+	"US West (N. California)":    "us-west-1",
+	"US West (Oregon)":           "us-west-2",
+	"Canada (Central)":           "ca-central-1",
+	"South America (Sao Paulo)":  "sa-east-1",
+	"EU (Ireland)":               "eu-west-1",
+	"EU (London)":                "eu-west-2",
+	"EU (Paris)":                 "eu-west-3",
+	"EU (Frankfurt)":             "eu-central-1",
+	"EU (Stockholm)":             "eu-north-1",
+	"EU (Milan)":                 "eu-south-1",
+	"Asia Pacific (Tokyo)":       "ap-northeast-1",
+	"Asia Pacific (Seoul)":       "ap-northeast-2",
+	"Asia Pacific (Osaka-Local)": "ap-northeast-3",
+	"Asia Pacific (Singapore)":   "ap-southeast-1",
+	"Asia Pacific (Sydney)":      "ap-southeast-2",
+	"Asia Pacific (Mumbai)":      "ap-south-1",
+	"Asia Pacific (Hong Kong)":   "ap-east-1",
+	"Middle East (Bahrain)":      "me-south-1",
+	"Africa (Cape Town)":         "af-south-1",
+}
+
+// offerEditions maps the editions of the commercial engines to their engine suffix
+var offerEditions = map[string]string{
+	"Enterprise":   "ee",
+	"Standard":     "se",
+	"Standard One": "se1",
+	"Standard Two": "se2",
+	"Express":      "ex",
+	"Web":          "web",
+}
+
+// offerVolumes maps the volume types of the offer files to storage types
+var offerVolumes = map[string]string{
+	"General Purpose":     "gp2",
+	"General Purpose-GP3": "gp3",
+	"Provisioned IOPS":    "io1",
+	"Magnetic":            "standard",
+}
+
+// priceList holds the on-demand prices of a region: instance prices in
+// dollars per hour, storage prices in dollars per GB-month and provisioned
+// iops prices in dollars per IOPS-month
+type priceList struct {
+	region    string
+	instances map[string]float64
+	storage   map[string]float64
+	iops      map[string]float64
+}
+
+func newPriceList(region string) *priceList {
+	return &priceList{
+		region:    region,
+		instances: map[string]float64{},
+		storage:   map[string]float64{},
+		iops:      map[string]float64{},
+	}
+}
+
+// loadPriceList loads the prices of the region from an AWS Price List bulk
+// JSON offer file, or from a CSV file if the path ends in .csv
+func loadPriceList(path, region string) (*priceList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p := newPriceList(region)
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		err = p.loadCSV(f)
+	} else {
+		err = p.loadOffer(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading price list %s: %v", path, err)
+	}
+	return p, nil
+}
+
+// deployment returns the deployment option of an instance
+func deployment(multiAZ bool) string {
+	if multiAZ {
+		return deploymentMultiAZ
+	}
+	return deploymentSingleAZ
+}
+
+// priceLicense normalizes a license model, prices don't distinguish the open
+// source licenses, general-public-license and postgresql-license
+func priceLicense(license string) string {
+	switch license {
+	case licenseIncluded, licenseBringYourOwn:
+		return license
+	default:
+		return licenseNotRequired
+	}
+}
+
+func instanceKey(class, engine, license, deployment string) string {
+	return strings.Join([]string{class, engine, priceLicense(license), deployment}, "|")
+}
+
+func storageKey(storageType, deployment string) string {
+	return storageType + "|" + deployment
+}
+
+// instancePrice returns the hourly on-demand price of the instance
+func (p *priceList) instancePrice(class, engine, license string, multiAZ bool) (float64, bool) {
+	v, ok := p.instances[instanceKey(class, engine, license, deployment(multiAZ))]
+	return v, ok
+}
+
+// storagePrice returns the monthly price of a GB of the storage type
+func (p *priceList) storagePrice(storageType string, multiAZ bool) (float64, bool) {
+	v, ok := p.storage[storageKey(storageType, deployment(multiAZ))]
+	return v, ok
+}
+
+// iopsPrice returns the monthly price of a provisioned iops of the storage type
+func (p *priceList) iopsPrice(storageType string, multiAZ bool) (float64, bool) {
+	v, ok := p.iops[storageKey(storageType, deployment(multiAZ))]
+	return v, ok
+}
+
+// loadCSV loads a simplified price list with a header row naming the columns
+// kind, region, class, engine, license_model, deployment, storage_type and
+// price. kind is instance, storage or iops.
+func (p *priceList) loadCSV(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"kind", "region", "class", "engine", "license_model", "deployment", "storage_type", "price"} {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("missing column %s", name)
+		}
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		field := func(name string) string {
+			return strings.TrimSpace(record[columns[name]])
+		}
+
+		if field("region") != p.region {
+			continue
+		}
+		price, err := strconv.ParseFloat(field("price"), 64)
+		if err != nil {
+			return fmt.Errorf("invalid price %q: %v", field("price"), err)
+		}
+		switch field("kind") {
+		case "instance":
+			p.instances[instanceKey(field("class"), field("engine"), field("license_model"), field("deployment"))] = price
+		case "storage":
+			p.storage[storageKey(field("storage_type"), field("deployment"))] = price
+		case "iops":
+			p.iops[storageKey(field("storage_type"), field("deployment"))] = price
+		default:
+			return fmt.Errorf("invalid kind %q", field("kind"))
+		}
+	}
+}
+
+// offerFile is the part of an AWS Price List bulk offer file the price list uses
+type offerFile struct {
+	Products map[string]struct {
+		ProductFamily string            `json:"productFamily"`
+		Attributes    map[string]string `json:"attributes"`
+	} `json:"products"`
+	Terms struct {
+		OnDemand map[string]map[string]struct {
+			PriceDimensions map[string]struct {
+				Unit         string            `json:"unit"`
+				BeginRange   string            `json:"beginRange"`
+				PricePerUnit map[string]string `json:"pricePerUnit"`
+			} `json:"priceDimensions"`
+		} `json:"OnDemand"`
+	} `json:"terms"`
+}
+
+// offerEngine returns the engine of an offer file product, as the RDS API names it
+func offerEngine(attributes map[string]string) string {
+	engine := attributes["databaseEngine"]
+	edition := offerEditions[attributes["databaseEdition"]]
+	switch engine {
+	case "MySQL":
+		return "mysql"
+	case "PostgreSQL":
+		return "postgres"
+	case "MariaDB":
+		return "mariadb"
+	case "Aurora MySQL":
+		return "aurora-mysql"
+	case "Aurora PostgreSQL":
+		return "aurora-postgresql"
+	case "Oracle":
+		return "oracle-" + edition
+	case "SQL Server":
+		return "sqlserver-" + edition
+	default:
+		return ""
+	}
+}
+
+// offerLicense returns the license model of an offer file product, as the RDS API names it
+func offerLicense(attributes map[string]string) string {
+	switch attributes["licenseModel"] {
+	case "License included":
+		return licenseIncluded
+	case "Bring your own license":
+		return licenseBringYourOwn
+	default:
+		return licenseNotRequired
+	}
+}
+
+// offerUnits are the units of the prices kept by product family
+var offerUnits = map[string]string{
+	"Database Instance": "Hrs",
+	"Database Storage":  "GB-Mo",
+	"Provisioned IOPS":  "IOPS-Mo",
+}
+
+// offerStorage is a storage or iops price with the engine of its product
+type offerStorage struct {
+	engine string
+	price  float64
+}
+
+// preferred reports whether the storage price o is preferred over prev for
+// the same volume type and deployment: the product for any engine, then the
+// cheapest one, so the price doesn't depend on the order of the offer file
+func (o offerStorage) preferred(prev offerStorage) bool {
+	anyEngine := func(engine string) bool {
+		return engine == "" || engine == "Any"
+	}
+	if anyEngine(o.engine) != anyEngine(prev.engine) {
+		return anyEngine(o.engine)
+	}
+	return o.price < prev.price
+}
+
+// offerSQLServerMirror is the deployment option SQL Server Multi-AZ instances
+// are listed with
+const offerSQLServerMirror = "Multi-AZ (SQL Server Mirror)"
+
+// offerDeployment returns the deployment option of an offer file product
+func offerDeployment(attributes map[string]string) string {
+	switch attributes["deploymentOption"] {
+	case "Single-AZ":
+		return deploymentSingleAZ
+	case "Multi-AZ", offerSQLServerMirror:
+		return deploymentMultiAZ
+	default:
+		return ""
+	}
+}
+
+// loadOffer loads the on-demand prices of an AWS Price List bulk offer file
+func (p *priceList) loadOffer(r io.Reader) error {
+	offer := offerFile{}
+	if err := json.NewDecoder(r).Decode(&offer); err != nil {
+		return err
+	}
+
+	storage := map[string]offerStorage{}
+	iops := map[string]offerStorage{}
+	for sku, product := range offer.Products {
+		a := product.Attributes
+		region := a["regionCode"]
+		if region == "" {
+			region = offerRegions[a["location"]]
+		}
+		d := offerDeployment(a)
+		if region != p.region || d == "" {
+			continue
+		}
+
+		// only the first tier of the unit of the product family is priced,
+		// free dimensions are skipped and the cheapest of the others wins
+		var price float64
+		found := false
+		for _, term := range offer.Terms.OnDemand[sku] {
+			for _, dim := range term.PriceDimensions {
+				if dim.Unit != offerUnits[product.ProductFamily] || (dim.BeginRange != "" && dim.BeginRange != "0") {
+					continue
+				}
+				v, err := strconv.ParseFloat(dim.PricePerUnit["USD"], 64)
+				if err != nil {
+					return fmt.Errorf("invalid price of %s: %v", sku, err)
+				}
+				if v > 0 && (!found || v < price) {
+					price, found = v, true
+				}
+			}
+		}
+		if !found {
+			continue
+		}
+
+		switch product.ProductFamily {
+		case "Database Instance":
+			engine := offerEngine(a)
+			if engine == "" {
+				continue
+			}
+			key := instanceKey(a["instanceType"], engine, offerLicense(a), d)
+			// a plain Multi-AZ product takes precedence over the mirror one
+			if _, ok := p.instances[key]; ok && a["deploymentOption"] == offerSQLServerMirror {
+				continue
+			}
+			p.instances[key] = price
+		case "Database Storage":
+			t, ok := offerVolumes[a["volumeType"]]
+			if !ok {
+				continue
+			}
+			o := offerStorage{engine: a["databaseEngine"], price: price}
+			if prev, ok := storage[storageKey(t, d)]; !ok || o.preferred(prev) {
+				storage[storageKey(t, d)] = o
+			}
+		case "Provisioned IOPS":
+			t, ok := offerVolumes[a["volumeType"]]
+			if !ok {
+				t = provisionedIopsVolume
+			}
+			o := offerStorage{engine: a["databaseEngine"], price: price}
+			if prev, ok := iops[storageKey(t, d)]; !ok || o.preferred(prev) {
+				iops[storageKey(t, d)] = o
+			}
+		}
+	}
+
+	for key, o := range storage {
+		p.storage[key] = o.price
+	}
+	for key, o := range iops {
+		p.iops[key] = o.price
+	}
+	return nil
+}
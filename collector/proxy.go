@@ -0,0 +1,247 @@
+package collector
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const (
+	proxyStatusAvailable = "available"
+	targetStateAvailable = "AVAILABLE"
+)
+
+// Metrics descriptions
+var (
+	proxyLabels            = []string{"region", "proxy"}
+	proxyTargetGroupLabels = []string{"region", "proxy", "target_group"}
+
+	proxyAvailable = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "proxy", "available"),
+		"Whether the RDS Proxy status is available (1) or not (0)",
+		[]string{"region", "proxy", "status"},
+		nil,
+	)
+
+	proxyInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "proxy", "info"),
+		"RDS Proxy engine family",
+		[]string{"region", "proxy", "engine_family"},
+		nil,
+	)
+
+	proxyIdleClientTimeout = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "proxy", "idle_client_timeout_seconds"),
+		"Seconds a client connection to the RDS Proxy can be idle before it is closed",
+		proxyLabels,
+		nil,
+	)
+
+	proxyRequireTLS = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "proxy", "require_tls"),
+		"Whether connections to the RDS Proxy must use TLS",
+		proxyLabels,
+		nil,
+	)
+
+	proxyTargetGroupMaxConnections = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "proxy_target_group", "max_connections_percent"),
+		"Connection pool size of the target group as percent of the target max_connections",
+		proxyTargetGroupLabels,
+		nil,
+	)
+
+	proxyTargetGroupMaxIdleConnections = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "proxy_target_group", "max_idle_connections_percent"),
+		"Idle connections the target group keeps as percent of the target max_connections",
+		proxyTargetGroupLabels,
+		nil,
+	)
+
+	proxyTargetAvailable = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "proxy_target", "available"),
+		"Whether the RDS Proxy target health state is AVAILABLE (1) or not (0)",
+		[]string{"region", "proxy", "target_group", "target", "type", "state", "reason"},
+		nil,
+	)
+)
+
+// ProxyGatherer is the interface that implements the methods required to gather RDS Proxy data
+type ProxyGatherer interface {
+	GetRDSProxies() ([]*types.DBProxy, error)
+	GetRDSProxyTargetGroups(proxyName string) ([]*types.DBProxyTargetGroup, error)
+	GetRDSProxyTargets(proxyName, targetGroupName string) ([]*types.DBProxyTarget, error)
+}
+
+// GetRDSProxies will get the proxies from the RDS API
+func (e *RDSClient) GetRDSProxies() ([]*types.DBProxy, error) {
+	ps := []*types.DBProxy{}
+	params := &rds.DescribeDBProxiesInput{
+		MaxRecords: aws.Int64(e.apiMaxResults),
+	}
+
+	err := e.client.DescribeDBProxiesPages(params, func(page *rds.DescribeDBProxiesOutput, lastPage bool) bool {
+		for _, p := range page.DBProxies {
+			ps = append(ps, &types.DBProxy{
+				Name:              aws.StringValue(p.DBProxyName),
+				Status:            aws.StringValue(p.Status),
+				EngineFamily:      aws.StringValue(p.EngineFamily),
+				IdleClientTimeout: float64(aws.Int64Value(p.IdleClientTimeout)),
+				RequireTLS:        aws.BoolValue(p.RequireTLS),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+// GetRDSProxyTargetGroups will get the target groups of the proxy from the RDS API
+func (e *RDSClient) GetRDSProxyTargetGroups(proxyName string) ([]*types.DBProxyTargetGroup, error) {
+	tgs := []*types.DBProxyTargetGroup{}
+	params := &rds.DescribeDBProxyTargetGroupsInput{
+		DBProxyName: aws.String(proxyName),
+		MaxRecords:  aws.Int64(e.apiMaxResults),
+	}
+
+	err := e.client.DescribeDBProxyTargetGroupsPages(params, func(page *rds.DescribeDBProxyTargetGroupsOutput, lastPage bool) bool {
+		for _, tg := range page.TargetGroups {
+			g := &types.DBProxyTargetGroup{
+				ProxyName: aws.StringValue(tg.DBProxyName),
+				Name:      aws.StringValue(tg.TargetGroupName),
+				Status:    aws.StringValue(tg.Status),
+				IsDefault: aws.BoolValue(tg.IsDefault),
+			}
+			if tg.ConnectionPoolConfig != nil {
+				g.MaxConnectionsPercent = float64(aws.Int64Value(tg.ConnectionPoolConfig.MaxConnectionsPercent))
+				g.MaxIdleConnectionsPercent = float64(aws.Int64Value(tg.ConnectionPoolConfig.MaxIdleConnectionsPercent))
+			}
+			tgs = append(tgs, g)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tgs, nil
+}
+
+// GetRDSProxyTargets will get the targets of the proxy target group from the RDS API
+func (e *RDSClient) GetRDSProxyTargets(proxyName, targetGroupName string) ([]*types.DBProxyTarget, error) {
+	ts := []*types.DBProxyTarget{}
+	params := &rds.DescribeDBProxyTargetsInput{
+		DBProxyName:     aws.String(proxyName),
+		TargetGroupName: aws.String(targetGroupName),
+		MaxRecords:      aws.Int64(e.apiMaxResults),
+	}
+
+	err := e.client.DescribeDBProxyTargetsPages(params, func(page *rds.DescribeDBProxyTargetsOutput, lastPage bool) bool {
+		for _, t := range page.Targets {
+			target := &types.DBProxyTarget{
+				ProxyName:       proxyName,
+				TargetGroupName: targetGroupName,
+				RdsResourceId:   aws.StringValue(t.RdsResourceId),
+				Type:            aws.StringValue(t.Type),
+			}
+			if t.TargetHealth != nil {
+				target.State = aws.StringValue(t.TargetHealth.State)
+				target.Reason = aws.StringValue(t.TargetHealth.Reason)
+			}
+			ts = append(ts, target)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ts, nil
+}
+
+// NewProxyCollector returns a collector for the RDS Proxies, their target
+// groups and the health of their targets.
+func NewProxyCollector(client ProxyGatherer, awsRegion string, logger log.Logger) *proxyCollector {
+	return &proxyCollector{
+		client: client,
+		region: awsRegion,
+		logger: logger,
+	}
+}
+
+type proxyCollector struct {
+	client ProxyGatherer
+	region string
+	logger log.Logger
+}
+
+// Describe describes the metrics exported by the proxy collector. It
+// implements prometheus.Collector.
+func (c *proxyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- proxyAvailable
+	ch <- proxyInfo
+	ch <- proxyIdleClientTimeout
+	ch <- proxyRequireTLS
+	ch <- proxyTargetGroupMaxConnections
+	ch <- proxyTargetGroupMaxIdleConnections
+	ch <- proxyTargetAvailable
+}
+
+// Collect fetches the proxies, target groups and targets and delivers them
+// as Prometheus metrics. It implements prometheus.Collector
+func (c *proxyCollector) Collect(ch chan<- prometheus.Metric) {
+	ps, err := c.client.GetRDSProxies()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS proxies", "err", err)
+		return
+	}
+
+	for _, p := range ps {
+		ch <- prometheus.MustNewConstMetric(
+			proxyAvailable, prometheus.GaugeValue, boolToFloat(p.Status == proxyStatusAvailable), c.region, p.Name, p.Status,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			proxyInfo, prometheus.GaugeValue, 1, c.region, p.Name, p.EngineFamily,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			proxyIdleClientTimeout, prometheus.GaugeValue, p.IdleClientTimeout, c.region, p.Name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			proxyRequireTLS, prometheus.GaugeValue, boolToFloat(p.RequireTLS), c.region, p.Name,
+		)
+
+		tgs, err := c.client.GetRDSProxyTargetGroups(p.Name)
+		if err != nil {
+			level.Error(c.logger).Log("msg", "Error getting RDS proxy target groups", "proxy", p.Name, "err", err)
+			continue
+		}
+
+		for _, tg := range tgs {
+			ch <- prometheus.MustNewConstMetric(
+				proxyTargetGroupMaxConnections, prometheus.GaugeValue, tg.MaxConnectionsPercent, c.region, p.Name, tg.Name,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				proxyTargetGroupMaxIdleConnections, prometheus.GaugeValue, tg.MaxIdleConnectionsPercent, c.region, p.Name, tg.Name,
+			)
+
+			ts, err := c.client.GetRDSProxyTargets(p.Name, tg.Name)
+			if err != nil {
+				level.Error(c.logger).Log("msg", "Error getting RDS proxy targets", "proxy", p.Name, "target_group", tg.Name, "err", err)
+				continue
+			}
+
+			for _, t := range ts {
+				ch <- prometheus.MustNewConstMetric(
+					proxyTargetAvailable, prometheus.GaugeValue, boolToFloat(t.State == targetStateAvailable), c.region, p.Name, tg.Name, t.RdsResourceId, t.Type, t.State, t.Reason,
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+type fakeProxyGatherer struct {
+	proxies []*types.DBProxy
+	groups  []*types.DBProxyTargetGroup
+	targets []*types.DBProxyTarget
+}
+
+func (f *fakeProxyGatherer) GetRDSProxies() ([]*types.DBProxy, error) {
+	return f.proxies, nil
+}
+
+func (f *fakeProxyGatherer) GetRDSProxyTargetGroups(proxyName string) ([]*types.DBProxyTargetGroup, error) {
+	return f.groups, nil
+}
+
+func (f *fakeProxyGatherer) GetRDSProxyTargets(proxyName, targetGroupName string) ([]*types.DBProxyTarget, error) {
+	return f.targets, nil
+}
+
+func TestGetRDSProxyTargets(t *testing.T) {
+	target := types.DBProxyTarget{RdsResourceId: "rds-dbinstance-1", Type: "RDS_INSTANCE", State: "UNAVAILABLE", Reason: "AUTH_FAILURE"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBProxyTargetsPages(t, mockRDS, false, target)
+
+	e := &RDSClient{
+		client: mockRDS,
+	}
+
+	ts, err := e.GetRDSProxyTargets("proxy-1", "default")
+	if err != nil {
+		t.Fatalf("Shouldn't return an error, but it did: %v", err)
+	}
+	if len(ts) != 1 {
+		t.Fatalf("Length in returned number of targets differs than expected, want: %d; got: %d", 1, len(ts))
+	}
+	got := ts[0]
+	if got.ProxyName != "proxy-1" || got.TargetGroupName != "default" || got.State != target.State || got.Reason != target.Reason {
+		t.Errorf("Wanted target %v in proxy-1/default, got %v", target, *got)
+	}
+}
+
+func TestProxyCollector(t *testing.T) {
+	client := &fakeProxyGatherer{
+		proxies: []*types.DBProxy{{Name: "proxy-1", Status: "available", EngineFamily: "POSTGRESQL", IdleClientTimeout: 1800, RequireTLS: true}},
+		groups:  []*types.DBProxyTargetGroup{{ProxyName: "proxy-1", Name: "default", MaxConnectionsPercent: 90, MaxIdleConnectionsPercent: 50}},
+		targets: []*types.DBProxyTarget{{RdsResourceId: "db-1", Type: "RDS_INSTANCE", State: "UNAVAILABLE", Reason: "CONNECTION_FAILED"}},
+	}
+	c := NewProxyCollector(client, "us-east-1", log.NewNopLogger())
+
+	want := `
+# HELP aws_rds_proxy_target_available Whether the RDS Proxy target health state is AVAILABLE (1) or not (0)
+# TYPE aws_rds_proxy_target_available gauge
+aws_rds_proxy_target_available{proxy="proxy-1",reason="CONNECTION_FAILED",region="us-east-1",state="UNAVAILABLE",target="db-1",target_group="default",type="RDS_INSTANCE"} 0
+# HELP aws_rds_proxy_target_group_max_connections_percent Connection pool size of the target group as percent of the target max_connections
+# TYPE aws_rds_proxy_target_group_max_connections_percent gauge
+aws_rds_proxy_target_group_max_connections_percent{proxy="proxy-1",region="us-east-1",target_group="default"} 90
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "aws_rds_proxy_target_available", "aws_rds_proxy_target_group_max_connections_percent"); err != nil {
+		t.Error(err)
+	}
+}
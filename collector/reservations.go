@@ -0,0 +1,364 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const (
+	reservationStateActive = "active"
+
+	recurringChargeHourly = "Hourly"
+
+	secondsPerYear = 365 * 24 * 60 * 60
+)
+
+// Metrics descriptions
+var (
+	onDemandInstances = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "reservation", "on_demand_instances"),
+		"Number of RDS instances of the class, product and deployment not covered by an active reservation",
+		[]string{"region", "class", "product", "multi_az"},
+		nil,
+	)
+
+	reservationOfferingLabels = []string{"region", "class", "product", "multi_az", "offering_type", "term"}
+
+	reservationSavings = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "reservation", "offering_estimated_savings_dollars"),
+		"Estimated savings over the term of reserving the on-demand RDS instances with the offering",
+		reservationOfferingLabels,
+		nil,
+	)
+
+	reservationSavingsRatio = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "reservation", "offering_savings_ratio"),
+		"Estimated savings of the offering over the term as a ratio of the on-demand cost",
+		reservationOfferingLabels,
+		nil,
+	)
+
+	reservationBreakEven = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "reservation", "offering_break_even_months"),
+		"Number of months after which the offering costs less than on-demand",
+		reservationOfferingLabels,
+		nil,
+	)
+)
+
+// ReservationGatherer is the interface that implements the methods required to gather reserved instance data
+type ReservationGatherer interface {
+	RDSGatherer
+	GetRDSReservedInstances() ([]*types.ReservedDBInstance, error)
+	GetRDSReservedInstancesOfferings(class, product string, multiAZ bool) ([]*types.ReservedDBInstancesOffering, error)
+}
+
+// GetRDSReservedInstances will get the purchased reserved instances from the RDS API
+func (e *RDSClient) GetRDSReservedInstances() ([]*types.ReservedDBInstance, error) {
+	rs := []*types.ReservedDBInstance{}
+	params := &rds.DescribeReservedDBInstancesInput{
+		MaxRecords: aws.Int64(e.apiMaxResults),
+	}
+
+	err := e.client.DescribeReservedDBInstancesPages(params, func(page *rds.DescribeReservedDBInstancesOutput, lastPage bool) bool {
+		for _, r := range page.ReservedDBInstances {
+			rs = append(rs, &types.ReservedDBInstance{
+				ID:                 aws.StringValue(r.ReservedDBInstanceId),
+				Class:              aws.StringValue(r.DBInstanceClass),
+				ProductDescription: aws.StringValue(r.ProductDescription),
+				MultiAZ:            aws.BoolValue(r.MultiAZ),
+				Count:              float64(aws.Int64Value(r.DBInstanceCount)),
+				State:              aws.StringValue(r.State),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rs, nil
+}
+
+// GetRDSReservedInstancesOfferings will get the reserved instance offerings
+// of the class, product and deployment from the RDS API
+func (e *RDSClient) GetRDSReservedInstancesOfferings(class, product string, multiAZ bool) ([]*types.ReservedDBInstancesOffering, error) {
+	offerings := []*types.ReservedDBInstancesOffering{}
+	params := &rds.DescribeReservedDBInstancesOfferingsInput{
+		DBInstanceClass:    aws.String(class),
+		ProductDescription: aws.String(product),
+		MultiAZ:            aws.Bool(multiAZ),
+		MaxRecords:         aws.Int64(e.apiMaxResults),
+	}
+
+	err := e.client.DescribeReservedDBInstancesOfferingsPages(params, func(page *rds.DescribeReservedDBInstancesOfferingsOutput, lastPage bool) bool {
+		for _, o := range page.ReservedDBInstancesOfferings {
+			recurring := 0.0
+			for _, c := range o.RecurringCharges {
+				if aws.StringValue(c.RecurringChargeFrequency) == recurringChargeHourly {
+					recurring += aws.Float64Value(c.RecurringChargeAmount)
+				}
+			}
+			offerings = append(offerings, &types.ReservedDBInstancesOffering{
+				ID:                 aws.StringValue(o.ReservedDBInstancesOfferingId),
+				Class:              aws.StringValue(o.DBInstanceClass),
+				ProductDescription: aws.StringValue(o.ProductDescription),
+				MultiAZ:            aws.BoolValue(o.MultiAZ),
+				OfferingType:       aws.StringValue(o.OfferingType),
+				Duration:           float64(aws.Int64Value(o.Duration)),
+				FixedPrice:         aws.Float64Value(o.FixedPrice),
+				UsagePrice:         aws.Float64Value(o.UsagePrice),
+				RecurringHourly:    recurring,
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return offerings, nil
+}
+
+// productDescription returns the product description reservations use for
+// the engine and license model, e.g. postgresql or oracle-se2(li)
+func productDescription(engine, license string) string {
+	if engine == "postgres" {
+		return "postgresql"
+	}
+	if strings.HasPrefix(engine, "oracle") || strings.HasPrefix(engine, "sqlserver") {
+		switch license {
+		case licenseIncluded:
+			return engine + "(li)"
+		case licenseBringYourOwn:
+			return engine + "(byol)"
+		}
+	}
+	return engine
+}
+
+// term returns the term of an offering duration in years, e.g. 1y
+func term(duration float64) string {
+	return fmt.Sprintf("%.0fy", duration/secondsPerYear)
+}
+
+// recommendation is a reserved instance offering compared with on-demand
+type recommendation struct {
+	Class             string  `json:"class"`
+	Product           string  `json:"product"`
+	MultiAZ           bool    `json:"multi_az"`
+	OfferingID        string  `json:"offering_id"`
+	OfferingType      string  `json:"offering_type"`
+	Term              string  `json:"term"`
+	Instances         float64 `json:"on_demand_instances"`
+	OnDemandHourly    float64 `json:"on_demand_hourly_dollars"`
+	EffectiveHourly   float64 `json:"effective_hourly_dollars"`
+	FixedPrice        float64 `json:"fixed_price_dollars"`
+	SavingsDollars    float64 `json:"estimated_savings_dollars"`
+	SavingsRatio      float64 `json:"savings_ratio"`
+	BreakEvenMonths   float64 `json:"break_even_months"`
+	breakEvenPossible bool
+}
+
+// recommend compares the offering with running the instances on-demand for its term
+func recommend(o *types.ReservedDBInstancesOffering, instances, onDemandHourly float64) *recommendation {
+	hours := o.Duration / 3600
+	hourly := o.UsagePrice + o.RecurringHourly
+	onDemand := onDemandHourly * hours
+	reserved := o.FixedPrice + hourly*hours
+
+	r := &recommendation{
+		Class:           o.Class,
+		Product:         o.ProductDescription,
+		MultiAZ:         o.MultiAZ,
+		OfferingID:      o.ID,
+		OfferingType:    o.OfferingType,
+		Term:            term(o.Duration),
+		Instances:       instances,
+		OnDemandHourly:  onDemandHourly,
+		EffectiveHourly: reserved / hours,
+		FixedPrice:      o.FixedPrice,
+		SavingsDollars:  (onDemand - reserved) * instances,
+	}
+	if onDemand > 0 {
+		r.SavingsRatio = (onDemand - reserved) / onDemand
+	}
+	// the upfront price is paid back by the hourly difference
+	if monthly := (onDemandHourly - hourly) * hoursPerMonth; monthly > 0 {
+		r.BreakEvenMonths = o.FixedPrice / monthly
+		r.breakEvenPossible = r.BreakEvenMonths <= hours/hoursPerMonth
+	}
+	return r
+}
+
+// NewReservationsCollector returns a collector that compares the reserved
+// instance offerings with the on-demand cost, from the price list at
+// priceListPath, of the instances not covered by a reservation. Offerings
+// are cached for cacheTTL.
+func NewReservationsCollector(client ReservationGatherer, awsRegion, priceListPath string, cacheTTL time.Duration, logger log.Logger) (*reservationsCollector, error) {
+	prices, err := loadPriceList(priceListPath, awsRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reservationsCollector{
+		client: client,
+		region: awsRegion,
+		prices: prices,
+		cache:  newTTLCache(cacheTTL),
+		logger: logger,
+	}, nil
+}
+
+type reservationsCollector struct {
+	client ReservationGatherer
+	region string
+	prices *priceList
+	cache  *ttlCache
+	logger log.Logger
+
+	mu              sync.Mutex
+	recommendations []*recommendation
+}
+
+// Describe describes the metrics exported by the reservations collector. It
+// implements prometheus.Collector.
+func (c *reservationsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- onDemandInstances
+	ch <- reservationSavings
+	ch <- reservationSavingsRatio
+	ch <- reservationBreakEven
+}
+
+// onDemandGroup is a class, product and deployment of running instances,
+// which is what a reservation applies to
+type onDemandGroup struct {
+	class   string
+	product string
+	multiAZ bool
+}
+
+// Collect compares the reserved instance offerings with the on-demand cost
+// of the instances not covered by a reservation, and delivers the result as
+// Prometheus metrics. It implements prometheus.Collector
+func (c *reservationsCollector) Collect(ch chan<- prometheus.Metric) {
+	rs, err := c.client.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+	reservations, err := c.client.GetRDSReservedInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS reserved instances", "err", err)
+		return
+	}
+
+	counts := map[onDemandGroup]float64{}
+	// the first instance of each group is priced, the engines and license
+	// models sharing a product description share their on-demand price
+	priced := map[onDemandGroup]*types.DBInstance{}
+	for _, r := range rs {
+		g := onDemandGroup{
+			class:   r.Class,
+			product: productDescription(r.Engine, r.LicenseModel),
+			multiAZ: r.MultiAZ,
+		}
+		counts[g]++
+		if _, ok := priced[g]; !ok {
+			priced[g] = r
+		}
+	}
+	for _, res := range reservations {
+		if res.State != reservationStateActive {
+			continue
+		}
+		g := onDemandGroup{class: res.Class, product: res.ProductDescription, multiAZ: res.MultiAZ}
+		if _, ok := counts[g]; ok {
+			counts[g] = math.Max(counts[g]-res.Count, 0)
+		}
+	}
+
+	recommendations := []*recommendation{}
+	for g, n := range counts {
+		ch <- prometheus.MustNewConstMetric(onDemandInstances, prometheus.GaugeValue, n, c.region, g.class, g.product, fmt.Sprint(g.multiAZ))
+		if n == 0 {
+			continue
+		}
+
+		inst := priced[g]
+		onDemandHourly, ok := c.prices.instancePrice(g.class, inst.Engine, inst.LicenseModel, g.multiAZ)
+		if !ok {
+			level.Debug(c.logger).Log("msg", "No on-demand price for RDS instance class", "class", g.class, "engine", inst.Engine)
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%s/%v", g.class, g.product, g.multiAZ)
+		v, err := c.cache.get(key, func() (interface{}, error) {
+			return c.client.GetRDSReservedInstancesOfferings(g.class, g.product, g.multiAZ)
+		})
+		if err != nil {
+			level.Error(c.logger).Log("msg", "Error getting RDS reserved instance offerings", "class", g.class, "product", g.product, "err", err)
+			continue
+		}
+
+		// keep the best offering of each type and term
+		best := map[string]*recommendation{}
+		for _, o := range v.([]*types.ReservedDBInstancesOffering) {
+			if o.Duration <= 0 {
+				continue
+			}
+			r := recommend(o, n, onDemandHourly)
+			if b, ok := best[r.OfferingType+"/"+r.Term]; !ok || r.SavingsDollars > b.SavingsDollars {
+				best[r.OfferingType+"/"+r.Term] = r
+			}
+		}
+
+		for _, r := range best {
+			recommendations = append(recommendations, r)
+
+			labelValues := []string{c.region, r.Class, r.Product, fmt.Sprint(r.MultiAZ), r.OfferingType, r.Term}
+			ch <- prometheus.MustNewConstMetric(reservationSavings, prometheus.GaugeValue, r.SavingsDollars, labelValues...)
+			ch <- prometheus.MustNewConstMetric(reservationSavingsRatio, prometheus.GaugeValue, r.SavingsRatio, labelValues...)
+			if r.breakEvenPossible {
+				ch <- prometheus.MustNewConstMetric(reservationBreakEven, prometheus.GaugeValue, r.BreakEvenMonths, labelValues...)
+			}
+		}
+	}
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		return recommendations[i].SavingsDollars > recommendations[j].SavingsDollars
+	})
+	c.mu.Lock()
+	c.recommendations = recommendations
+	c.mu.Unlock()
+}
+
+// ServeHTTP writes the offerings that save money, from the last scrape,
+// ranked by estimated savings as JSON
+func (c *reservationsCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	result := []*recommendation{}
+	for _, rec := range c.recommendations {
+		if rec.SavingsDollars > 0 {
+			result = append(result, rec)
+		}
+	}
+	c.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,135 @@
+package collector
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const testReservationsPriceList = `kind,region,class,engine,license_model,deployment,storage_type,price
+instance,us-east-1,db.m5.large,mysql,,single-az,,0.25
+instance,us-east-1,db.r5.large,postgres,,multi-az,,1
+`
+
+func TestProductDescription(t *testing.T) {
+	tests := []struct {
+		engine, license, want string
+	}{
+		{"mysql", "general-public-license", "mysql"},
+		{"postgres", "postgresql-license", "postgresql"},
+		{"oracle-se2", "license-included", "oracle-se2(li)"},
+		{"oracle-ee", "bring-your-own-license", "oracle-ee(byol)"},
+		{"aurora-mysql", "general-public-license", "aurora-mysql"},
+	}
+
+	for _, test := range tests {
+		if got := productDescription(test.engine, test.license); got != test.want {
+			t.Errorf("productDescription(%q, %q) = %q, want %q", test.engine, test.license, got, test.want)
+		}
+	}
+}
+
+func TestReservationsCollector(t *testing.T) {
+	path, cleanup := writePriceList(t, "prices.csv", testReservationsPriceList)
+	defer cleanup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	m5 := types.DBInstance{Class: "db.m5.large", Engine: "mysql", LicenseModel: "general-public-license"}
+	awsMock.MockDescribeDBInstances(t, mockRDS, false,
+		types.DBInstance{Identifier: "db-1", Class: m5.Class, Engine: m5.Engine, LicenseModel: m5.LicenseModel},
+		types.DBInstance{Identifier: "db-2", Class: m5.Class, Engine: m5.Engine, LicenseModel: m5.LicenseModel},
+		types.DBInstance{Identifier: "db-3", Class: m5.Class, Engine: m5.Engine, LicenseModel: m5.LicenseModel},
+		types.DBInstance{Identifier: "db-4", Class: "db.r5.large", Engine: "postgres", LicenseModel: "postgresql-license", MultiAZ: true},
+	)
+	awsMock.MockDescribeReservedDBInstancesPages(t, mockRDS, false,
+		types.ReservedDBInstance{ID: "ri-1", Class: "db.m5.large", ProductDescription: "mysql", Count: 1, State: "active"},
+		types.ReservedDBInstance{ID: "ri-2", Class: "db.r5.large", ProductDescription: "postgresql", MultiAZ: true, Count: 1, State: "active"},
+		types.ReservedDBInstance{ID: "ri-3", Class: "db.m5.large", ProductDescription: "mysql", Count: 5, State: "retired"},
+	)
+	awsMock.MockDescribeReservedDBInstancesOfferingsPages(t, mockRDS, false,
+		types.ReservedDBInstancesOffering{ID: "no-upfront", Class: "db.m5.large", ProductDescription: "mysql", OfferingType: "No Upfront", Duration: secondsPerYear, RecurringHourly: 0.125},
+		types.ReservedDBInstancesOffering{ID: "all-upfront", Class: "db.m5.large", ProductDescription: "mysql", OfferingType: "All Upfront", Duration: secondsPerYear, FixedPrice: 912.5},
+		types.ReservedDBInstancesOffering{ID: "worse", Class: "db.m5.large", ProductDescription: "mysql", OfferingType: "All Upfront", Duration: secondsPerYear, FixedPrice: 2000},
+		types.ReservedDBInstancesOffering{ID: "multi-az", Class: "db.m5.large", ProductDescription: "mysql", MultiAZ: true, OfferingType: "No Upfront", Duration: secondsPerYear, RecurringHourly: 0.25},
+	)
+
+	c, err := NewReservationsCollector(&RDSClient{client: mockRDS}, "us-east-1", path, time.Hour, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("Shouldn't return an error, but it did: %v", err)
+	}
+
+	want := `
+# HELP aws_rds_reservation_offering_break_even_months Number of months after which the offering costs less than on-demand
+# TYPE aws_rds_reservation_offering_break_even_months gauge
+aws_rds_reservation_offering_break_even_months{class="db.m5.large",multi_az="false",offering_type="All Upfront",product="mysql",region="us-east-1",term="1y"} 5
+aws_rds_reservation_offering_break_even_months{class="db.m5.large",multi_az="false",offering_type="No Upfront",product="mysql",region="us-east-1",term="1y"} 0
+# HELP aws_rds_reservation_offering_estimated_savings_dollars Estimated savings over the term of reserving the on-demand RDS instances with the offering
+# TYPE aws_rds_reservation_offering_estimated_savings_dollars gauge
+aws_rds_reservation_offering_estimated_savings_dollars{class="db.m5.large",multi_az="false",offering_type="All Upfront",product="mysql",region="us-east-1",term="1y"} 2555
+aws_rds_reservation_offering_estimated_savings_dollars{class="db.m5.large",multi_az="false",offering_type="No Upfront",product="mysql",region="us-east-1",term="1y"} 2190
+# HELP aws_rds_reservation_on_demand_instances Number of RDS instances of the class, product and deployment not covered by an active reservation
+# TYPE aws_rds_reservation_on_demand_instances gauge
+aws_rds_reservation_on_demand_instances{class="db.m5.large",multi_az="false",product="mysql",region="us-east-1"} 2
+aws_rds_reservation_on_demand_instances{class="db.r5.large",multi_az="true",product="postgresql",region="us-east-1"} 0
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want),
+		"aws_rds_reservation_offering_break_even_months", "aws_rds_reservation_offering_estimated_savings_dollars",
+		"aws_rds_reservation_on_demand_instances"); err != nil {
+		t.Error(err)
+	}
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/reservations/recommendations", nil))
+	result := []recommendation{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || result[0].OfferingID != "all-upfront" || result[1].OfferingID != "no-upfront" {
+		t.Errorf("Wanted the all-upfront then no-upfront offerings, got %+v", result)
+	}
+}
+
+func TestReservationsCollectorSharedProduct(t *testing.T) {
+	path, cleanup := writePriceList(t, "prices.csv", testReservationsPriceList)
+	defer cleanup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	// both license models map to the mysql product description
+	awsMock.MockDescribeDBInstances(t, mockRDS, false,
+		types.DBInstance{Identifier: "db-1", Class: "db.m5.large", Engine: "mysql", LicenseModel: "general-public-license"},
+		types.DBInstance{Identifier: "db-2", Class: "db.m5.large", Engine: "mysql"},
+	)
+	awsMock.MockDescribeReservedDBInstancesPages(t, mockRDS, false,
+		types.ReservedDBInstance{ID: "ri-1", Class: "db.m5.large", ProductDescription: "mysql", Count: 1, State: "active"},
+	)
+	awsMock.MockDescribeReservedDBInstancesOfferingsPages(t, mockRDS, false)
+
+	c, err := NewReservationsCollector(&RDSClient{client: mockRDS}, "us-east-1", path, time.Hour, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("Shouldn't return an error, but it did: %v", err)
+	}
+
+	want := `
+# HELP aws_rds_reservation_on_demand_instances Number of RDS instances of the class, product and deployment not covered by an active reservation
+# TYPE aws_rds_reservation_on_demand_instances gauge
+aws_rds_reservation_on_demand_instances{class="db.m5.large",multi_az="false",product="mysql",region="us-east-1"} 1
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "aws_rds_reservation_on_demand_instances"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,398 @@
+package collector
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+// maxFingerprintLabelLength caps the length of the fingerprint label value
+const maxFingerprintLabelLength = 200
+
+// Metrics descriptions
+var (
+	slowQueryDigestCalls = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "slow_query_digest", "calls_total"),
+		"Number of slow queries of the digest, for the top digests by total time",
+		[]string{"region", "instance", "digest"},
+		nil,
+	)
+
+	slowQueryDigestSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "slow_query_digest", "seconds_total"),
+		"Total time spent in slow queries of the digest, for the top digests by total time",
+		[]string{"region", "instance", "digest"},
+		nil,
+	)
+
+	slowQueryDigestInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "slow_query_digest", "info"),
+		"Normalized query of the digest, for the top digests by total time",
+		[]string{"region", "instance", "digest", "fingerprint"},
+		nil,
+	)
+)
+
+var (
+	sqlBlockComment  = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	sqlLineComment   = regexp.MustCompile(`(?m)--.*$`)
+	sqlHashComment   = regexp.MustCompile(`(?m)#.*$`)
+	sqlString        = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'`)
+	sqlHexNumber     = regexp.MustCompile(`\b0x[0-9a-fA-F]+\b`)
+	sqlNumber        = regexp.MustCompile(`\b\d+(?:\.\d+)?(?:[eE][-+]?\d+)?\b`)
+	sqlPlaceholder   = regexp.MustCompile(`\$\d+`)
+	sqlNegative      = regexp.MustCompile(`([=(,<>]\s*)-\s*\?`)
+	sqlInList        = regexp.MustCompile(`(?i)\bin\s*\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	sqlValuesList    = regexp.MustCompile(`(?i)\bvalues\s*\(\s*\?(?:\s*,\s*\?)*\s*\)(?:\s*,\s*\(\s*\?(?:\s*,\s*\?)*\s*\))*`)
+	sqlWhitespace    = regexp.MustCompile(`\s+`)
+	postgresDuration = regexp.MustCompile(`duration: ([0-9.]+) ms\s+(?:statement|execute [^:]*): (.*)$`)
+	mysqlQueryTime   = regexp.MustCompile(`^# Query_time: ([0-9.]+)`)
+)
+
+// fingerprint normalizes a query by stripping comments and literals, so
+// queries differing only in their parameters share the same fingerprint
+func fingerprint(query string, mysql bool) string {
+	q := sqlBlockComment.ReplaceAllString(query, " ")
+	q = sqlString.ReplaceAllString(q, "?")
+	q = sqlLineComment.ReplaceAllString(q, "")
+	// # starts a comment in MySQL only, it is an operator in PostgreSQL
+	if mysql {
+		q = sqlHashComment.ReplaceAllString(q, "")
+	}
+	q = sqlPlaceholder.ReplaceAllString(q, "?")
+	q = sqlHexNumber.ReplaceAllString(q, "?")
+	q = sqlNumber.ReplaceAllString(q, "?")
+	q = sqlNegative.ReplaceAllString(q, "$1?")
+	q = sqlInList.ReplaceAllString(q, "in (?+)")
+	q = sqlValuesList.ReplaceAllString(q, "values (?+)")
+	q = sqlWhitespace.ReplaceAllString(q, " ")
+	q = strings.TrimSuffix(strings.TrimSpace(q), ";")
+	return strings.ToLower(strings.TrimSpace(q))
+}
+
+func digestOf(fp string) string {
+	sum := sha1.Sum([]byte(fp))
+	return hex.EncodeToString(sum[:8])
+}
+
+// slowQuery is a query read from a slow log
+type slowQuery struct {
+	seconds float64
+	query   string
+	mysql   bool // whether the query was read from a MySQL slow log
+}
+
+// slowLogParser parses the slow query entries of a log. Entries can span
+// portions of the log, so a parser keeps its state between calls to feed.
+type slowLogParser struct {
+	mysql   bool
+	pending *slowQuery
+	lines   []string
+}
+
+// feed parses the log data and returns the entries completed by it
+func (p *slowLogParser) feed(data string) []slowQuery {
+	qs := []slowQuery{}
+	for _, line := range strings.Split(data, "\n") {
+		if p.mysql {
+			qs = p.feedMySQL(line, qs)
+		} else {
+			qs = p.feedPostgres(line, qs)
+		}
+	}
+	return qs
+}
+
+func (p *slowLogParser) flush(qs []slowQuery) []slowQuery {
+	if p.pending != nil && len(p.lines) > 0 {
+		p.pending.query = strings.Join(p.lines, "\n")
+		p.pending.mysql = p.mysql
+		qs = append(qs, *p.pending)
+	}
+	p.pending = nil
+	p.lines = nil
+	return qs
+}
+
+// feedMySQL parses the MySQL slow log format:
+//
+//	# Time: 2020-10-01T12:00:00.123456Z
+//	# User@Host: app[app] @  [10.0.0.1]  Id:    12
+//	# Query_time: 2.000123  Lock_time: 0.000050 Rows_sent: 1  Rows_examined: 100000
+//	SET timestamp=1601553600;
+//	SELECT * FROM orders WHERE id = 42;
+func (p *slowLogParser) feedMySQL(line string, qs []slowQuery) []slowQuery {
+	if m := mysqlQueryTime.FindStringSubmatch(line); m != nil {
+		qs = p.flush(qs)
+		seconds, _ := strconv.ParseFloat(m[1], 64)
+		p.pending = &slowQuery{seconds: seconds}
+		return qs
+	}
+	if p.pending == nil || strings.HasPrefix(line, "#") {
+		return qs
+	}
+
+	lower := strings.ToLower(strings.TrimSpace(line))
+	if len(p.lines) == 0 && (lower == "" || strings.HasPrefix(lower, "set timestamp=") || strings.HasPrefix(lower, "use ")) {
+		return qs
+	}
+	p.lines = append(p.lines, line)
+	if strings.HasSuffix(lower, ";") {
+		qs = p.flush(qs)
+	}
+	return qs
+}
+
+// feedPostgres parses the PostgreSQL log_min_duration_statement lines, with
+// continuation lines of multi line statements starting with a tab:
+//
+//	2020-10-01 12:00:00 UTC:10.0.0.1(5432):app@db:[1234]:LOG:  duration: 2001.123 ms  statement: SELECT 1
+func (p *slowLogParser) feedPostgres(line string, qs []slowQuery) []slowQuery {
+	if m := postgresDuration.FindStringSubmatch(line); m != nil {
+		qs = p.flush(qs)
+		ms, _ := strconv.ParseFloat(m[1], 64)
+		p.pending = &slowQuery{seconds: ms / 1000}
+		p.lines = []string{m[2]}
+		return qs
+	}
+	if p.pending != nil && strings.HasPrefix(line, "\t") {
+		p.lines = append(p.lines, line)
+		return qs
+	}
+	if line != "" {
+		qs = p.flush(qs)
+	}
+	return qs
+}
+
+// queryDigest aggregates the slow queries sharing a fingerprint
+type queryDigest struct {
+	Digest       string  `json:"digest"`
+	Fingerprint  string  `json:"fingerprint"`
+	Calls        float64 `json:"calls"`
+	TotalSeconds float64 `json:"total_seconds"`
+	MaxSeconds   float64 `json:"max_seconds"`
+}
+
+// slowLogFile returns the function picking the slow log of the engine, or
+// nil if the engine is not supported. PostgreSQL logs slow statements to its
+// error log.
+func slowLogFile(engine string) func([]*types.DBLogFile) *types.DBLogFile {
+	switch {
+	case strings.Contains(engine, "mysql"), strings.Contains(engine, "mariadb"):
+		return func(files []*types.DBLogFile) *types.DBLogFile {
+			var active *types.DBLogFile
+			for _, f := range files {
+				if strings.HasPrefix(f.Name, "slowquery/") && (active == nil || f.LastWritten.After(active.LastWritten)) {
+					active = f
+				}
+			}
+			return active
+		}
+	case strings.Contains(engine, "postgres"):
+		return activeErrorLog
+	}
+	return nil
+}
+
+// NewSlowQueriesCollector returns a collector that follows the slow query
+// logs of the RDS instances and aggregates the queries by fingerprint.
+// At most maxFingerprints are kept per instance, the top ones by total time
+// are exported.
+func NewSlowQueriesCollector(client LogTailGatherer, awsRegion string, top, maxFingerprints, maxBytes int, logger log.Logger) *slowQueriesCollector {
+	return &slowQueriesCollector{
+		client:          client,
+		region:          awsRegion,
+		top:             top,
+		maxFingerprints: maxFingerprints,
+		logger:          logger,
+		follower:        newLogFollower(client, maxBytes),
+		parsers:         map[string]*slowLogParser{},
+		digests:         map[string]map[string]*queryDigest{},
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "slow_query",
+			Name:      "duration_seconds",
+			Help:      "Duration of the queries in the slow query log of the RDS instance",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300},
+		}, labels),
+	}
+}
+
+type slowQueriesCollector struct {
+	client          LogTailGatherer
+	region          string
+	top             int
+	maxFingerprints int
+	logger          log.Logger
+	latency         *prometheus.HistogramVec
+
+	mu       sync.Mutex
+	follower *logFollower
+	parsers  map[string]*slowLogParser
+	digests  map[string]map[string]*queryDigest
+}
+
+// Run polls the slow query logs every interval, it never returns
+func (c *slowQueriesCollector) Run(interval time.Duration) {
+	for {
+		c.poll()
+		time.Sleep(interval)
+	}
+}
+
+// poll reads the new entries of the slow query log of every instance
+func (c *slowQueriesCollector) poll() {
+	rs, err := c.client.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+
+	for _, r := range rs {
+		selectFile := slowLogFile(r.Engine)
+		if selectFile == nil {
+			continue
+		}
+
+		c.mu.Lock()
+		instance := r.Identifier
+		parser, ok := c.parsers[instance]
+		if !ok {
+			parser = &slowLogParser{mysql: !strings.Contains(r.Engine, "postgres")}
+			c.parsers[instance] = parser
+		}
+		err := c.follower.follow(instance, selectFile, func(data string) {
+			for _, q := range parser.feed(data) {
+				c.record(instance, q)
+			}
+		})
+		if err != nil {
+			level.Error(c.logger).Log("msg", "Error tailing RDS slow query log", "instance", instance, "err", err)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// record adds the query to the digest table of the instance, evicting the
+// digest with the least total time when the table is full
+func (c *slowQueriesCollector) record(instance string, q slowQuery) {
+	c.latency.WithLabelValues(c.region, instance).Observe(q.seconds)
+
+	ds, ok := c.digests[instance]
+	if !ok {
+		ds = map[string]*queryDigest{}
+		c.digests[instance] = ds
+	}
+
+	fp := fingerprint(q.query, q.mysql)
+	digest := digestOf(fp)
+	d, ok := ds[digest]
+	if !ok {
+		if len(ds) >= c.maxFingerprints {
+			var least *queryDigest
+			for _, d := range ds {
+				if least == nil || d.TotalSeconds < least.TotalSeconds {
+					least = d
+				}
+			}
+			delete(ds, least.Digest)
+		}
+		d = &queryDigest{Digest: digest, Fingerprint: fp}
+		ds[digest] = d
+	}
+
+	d.Calls++
+	d.TotalSeconds += q.seconds
+	if q.seconds > d.MaxSeconds {
+		d.MaxSeconds = q.seconds
+	}
+}
+
+// topDigests returns copies of the n digests of the instance with the most total time
+func (c *slowQueriesCollector) topDigests(instance string, n int) []queryDigest {
+	ds := []queryDigest{}
+	for _, d := range c.digests[instance] {
+		ds = append(ds, *d)
+	}
+	sort.Slice(ds, func(i, j int) bool { return ds[i].TotalSeconds > ds[j].TotalSeconds })
+	if len(ds) > n {
+		ds = ds[:n]
+	}
+	return ds
+}
+
+// Describe describes the metrics exported by the slow queries collector. It
+// implements prometheus.Collector.
+func (c *slowQueriesCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.latency.Describe(ch)
+	ch <- slowQueryDigestCalls
+	ch <- slowQueryDigestSeconds
+	ch <- slowQueryDigestInfo
+}
+
+// Collect delivers the slow query latency and the top digests as Prometheus
+// metrics. It implements prometheus.Collector
+func (c *slowQueriesCollector) Collect(ch chan<- prometheus.Metric) {
+	c.latency.Collect(ch)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for instance := range c.digests {
+		for _, d := range c.topDigests(instance, c.top) {
+			// label values must be valid UTF-8, the log may not be
+			fp := truncate(strings.ToValidUTF8(d.Fingerprint, "?"), maxFingerprintLabelLength)
+			ch <- prometheus.MustNewConstMetric(
+				slowQueryDigestCalls, prometheus.CounterValue, d.Calls, c.region, instance, d.Digest,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				slowQueryDigestSeconds, prometheus.CounterValue, d.TotalSeconds, c.region, instance, d.Digest,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				slowQueryDigestInfo, prometheus.GaugeValue, 1, c.region, instance, d.Digest, fp,
+			)
+		}
+	}
+}
+
+// ServeHTTP writes the top slow query digests per instance as JSON. The
+// instance and top query parameters restrict the instance and the number of digests.
+func (c *slowQueriesCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	top := c.top
+	if v := r.URL.Query().Get("top"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid top parameter", http.StatusBadRequest)
+			return
+		}
+		top = n
+	}
+	only := r.URL.Query().Get("instance")
+
+	c.mu.Lock()
+	result := map[string][]queryDigest{}
+	for instance := range c.digests {
+		if only != "" && instance != only {
+			continue
+		}
+		result[instance] = c.topDigests(instance, top)
+	}
+	c.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,126 @@
+package collector
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		query string
+		mysql bool
+		want  string
+	}{
+		{"SELECT * FROM orders WHERE id = 42;", true, "select * from orders where id = ?"},
+		{"select *  from orders\n where id=-7 and name = 'O''Brien'", true, "select * from orders where id=? and name = ?"},
+		{"SELECT * FROM t1 WHERE id IN (1, 2, 3) /* app:checkout */", true, "select * from t1 where id in (?+)"},
+		{"INSERT INTO events (a, b) VALUES (1, 'x'), (2, 'y')", true, "insert into events (a, b) values (?+)"},
+		{"SELECT * FROM users WHERE email = $1 AND flags & 0x1F", false, "select * from users where email = ? and flags & ?"},
+		{"SELECT * FROM orders WHERE id = 42 # checkout\n-- retried", true, "select * from orders where id = ?"},
+		{"SELECT data #>> '{a,b}' FROM docs WHERE flags # 4 = 0 -- lookup", false, "select data #>> ? from docs where flags # ? = ?"},
+	}
+
+	for _, test := range tests {
+		if got := fingerprint(test.query, test.mysql); got != test.want {
+			t.Errorf("fingerprint(%q): wanted %q, got %q", test.query, test.want, got)
+		}
+	}
+}
+
+func TestSlowLogParserMySQL(t *testing.T) {
+	p := &slowLogParser{mysql: true}
+	qs := p.feed(`# Time: 2020-10-01T12:00:00.123456Z
+# User@Host: app[app] @  [10.0.0.1]  Id:    12
+# Query_time: 2.500000  Lock_time: 0.000050 Rows_sent: 1  Rows_examined: 100000
+use shop;
+SET timestamp=1601553600;
+SELECT *
+FROM orders WHERE id = 42;
+# Time: 2020-10-01T12:00:01.000000Z
+# User@Host: app[app] @  [10.0.0.1]  Id:    12
+# Query_time: 1.000000  Lock_time: 0.000050 Rows_sent: 1  Rows_examined: 100000
+SET timestamp=1601553601;
+`)
+	// the second entry is split across portions
+	qs = append(qs, p.feed("SELECT * FROM orders WHERE id = 43;\n")...)
+
+	if len(qs) != 2 {
+		t.Fatalf("Wanted 2 slow queries, got %d: %v", len(qs), qs)
+	}
+	if qs[0].seconds != 2.5 || !qs[0].mysql || fingerprint(qs[0].query, qs[0].mysql) != "select * from orders where id = ?" {
+		t.Errorf("Wanted a 2.5s query on orders, got %v", qs[0])
+	}
+	if fingerprint(qs[0].query, qs[0].mysql) != fingerprint(qs[1].query, qs[1].mysql) {
+		t.Errorf("Wanted both queries to share a fingerprint, got %q and %q", qs[0].query, qs[1].query)
+	}
+}
+
+func TestSlowLogParserPostgres(t *testing.T) {
+	p := &slowLogParser{}
+	qs := p.feed("2020-10-01 12:00:00 UTC:10.0.0.1(5432):app@shop:[1234]:LOG:  duration: 2001.500 ms  statement: SELECT *\n" +
+		"\tFROM orders WHERE id = 42\n" +
+		"2020-10-01 12:00:01 UTC:10.0.0.1(5432):app@shop:[1234]:LOG:  duration: 500.000 ms  execute <unnamed>: SELECT 1\n" +
+		"2020-10-01 12:00:02 UTC:10.0.0.1(5432):app@shop:[1234]:LOG:  checkpoint starting: time\n")
+
+	if len(qs) != 2 {
+		t.Fatalf("Wanted 2 slow queries, got %d: %v", len(qs), qs)
+	}
+	if qs[0].seconds != 2.0015 || qs[0].mysql || fingerprint(qs[0].query, qs[0].mysql) != "select * from orders where id = ?" {
+		t.Errorf("Wanted a 2.0015s query on orders, got %v", qs[0])
+	}
+	if qs[1].seconds != 0.5 {
+		t.Errorf("Wanted a 0.5s query, got %v", qs[1])
+	}
+}
+
+func TestSlowQueriesFingerprintLabel(t *testing.T) {
+	c := NewSlowQueriesCollector(nil, "us-east-1", 1, 10, 1<<20, log.NewNopLogger())
+	// an invalid UTF-8 byte, and multi-byte identifiers past the label length
+	query := "SELECT * FROM \"caf\xe9\" JOIN \"" + strings.Repeat("é", 250) + "\" ON id = 1"
+	c.record("db-1", slowQuery{seconds: 1, query: query})
+
+	label := "select * from \\\"caf\ufffd\\\" join \\\""
+	label += strings.Repeat("é", maxFingerprintLabelLength-utf8.RuneCountInString("select * from \"caf\ufffd\" join \""))
+	want := `
+# HELP aws_rds_slow_query_digest_info Normalized query of the digest, for the top digests by total time
+# TYPE aws_rds_slow_query_digest_info gauge
+aws_rds_slow_query_digest_info{digest="` + digestOf(fingerprint(query, false)) + `",fingerprint="` + label + `",instance="db-1",region="us-east-1"} 1
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "aws_rds_slow_query_digest_info"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSlowQueriesDigestTable(t *testing.T) {
+	c := NewSlowQueriesCollector(nil, "us-east-1", 1, 2, 1<<20, log.NewNopLogger())
+	c.record("db-1", slowQuery{seconds: 5, query: "SELECT * FROM a WHERE id = 1"})
+	c.record("db-1", slowQuery{seconds: 1, query: "SELECT * FROM b WHERE id = 1"})
+	c.record("db-1", slowQuery{seconds: 3, query: "SELECT * FROM a WHERE id = 2"})
+	// the table is full, the digest of b has the least total time and is evicted
+	c.record("db-1", slowQuery{seconds: 2, query: "SELECT * FROM c WHERE id = 1"})
+
+	if len(c.digests["db-1"]) != 2 {
+		t.Fatalf("Wanted the digest table bounded to 2 entries, got %d", len(c.digests["db-1"]))
+	}
+	if _, ok := c.digests["db-1"][digestOf("select * from b where id = ?")]; ok {
+		t.Errorf("Wanted the digest of b to be evicted")
+	}
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow-queries?instance=db-1", nil))
+	result := map[string][]queryDigest{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	top := result["db-1"]
+	if len(top) != 1 || top[0].Fingerprint != "select * from a where id = ?" || top[0].Calls != 2 || top[0].TotalSeconds != 8 {
+		t.Errorf("Wanted the digest of a with 2 calls and 8s as top digest, got %+v", top)
+	}
+}
@@ -0,0 +1,141 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const sourceRegionStatusAvailable = "available"
+
+// Metrics descriptions
+var (
+	sourceRegionInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "source_region", "info"),
+		"Region the configured region can create read replicas and copy snapshots from, with its status",
+		[]string{"region", "source_region", "status"},
+		nil,
+	)
+
+	sourceRegionAvailable = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "source_region", "available"),
+		"Whether the source region is available for replication",
+		[]string{"region", "source_region"},
+		nil,
+	)
+
+	crossRegionReplicaSupported = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cross_region_replica", "source_supported"),
+		"Whether the source region of the cross-region read replica is still listed as an available source region",
+		[]string{"region", "instance", "source_region", "source"},
+		nil,
+	)
+)
+
+// SourceRegionGatherer is the interface that implements the methods required to gather source regions
+type SourceRegionGatherer interface {
+	RDSGatherer
+	GetRDSSourceRegions() ([]*types.SourceRegion, error)
+}
+
+// GetRDSSourceRegions will get the regions the configured region can replicate from from the RDS API
+func (e *RDSClient) GetRDSSourceRegions() ([]*types.SourceRegion, error) {
+	srs := []*types.SourceRegion{}
+	params := &rds.DescribeSourceRegionsInput{
+		MaxRecords: aws.Int64(e.apiMaxResults),
+	}
+
+	err := e.client.DescribeSourceRegionsPages(params, func(page *rds.DescribeSourceRegionsOutput, lastPage bool) bool {
+		for _, sr := range page.SourceRegions {
+			srs = append(srs, &types.SourceRegion{
+				Name:     aws.StringValue(sr.RegionName),
+				Endpoint: aws.StringValue(sr.Endpoint),
+				Status:   aws.StringValue(sr.Status),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return srs, nil
+}
+
+// replicaSourceRegion returns the region of the source of a cross-region read
+// replica. Sources in the same region are plain identifiers, not ARNs.
+func replicaSourceRegion(source string) (string, bool) {
+	if !strings.HasPrefix(source, "arn:") {
+		return "", false
+	}
+	a, err := arn.Parse(source)
+	if err != nil {
+		return "", false
+	}
+	return a.Region, true
+}
+
+// NewSourceRegionsCollector returns a collector for the source regions of the
+// configured region and the cross-region read replicas replicating from them
+func NewSourceRegionsCollector(client SourceRegionGatherer, awsRegion string, logger log.Logger) *sourceRegionsCollector {
+	return &sourceRegionsCollector{
+		client: client,
+		region: awsRegion,
+		logger: logger,
+	}
+}
+
+type sourceRegionsCollector struct {
+	client SourceRegionGatherer
+	region string
+	logger log.Logger
+}
+
+// Describe describes the metrics exported by the source regions collector.
+// It implements prometheus.Collector.
+func (c *sourceRegionsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sourceRegionInfo
+	ch <- sourceRegionAvailable
+	ch <- crossRegionReplicaSupported
+}
+
+// Collect fetches the source regions and the RDS instances, and delivers
+// them as Prometheus metrics. It implements prometheus.Collector
+func (c *sourceRegionsCollector) Collect(ch chan<- prometheus.Metric) {
+	srs, err := c.client.GetRDSSourceRegions()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS source regions", "err", err)
+		return
+	}
+
+	available := map[string]bool{}
+	for _, sr := range srs {
+		available[sr.Name] = sr.Status == sourceRegionStatusAvailable
+		ch <- prometheus.MustNewConstMetric(sourceRegionInfo, prometheus.GaugeValue, 1, c.region, sr.Name, sr.Status)
+		ch <- prometheus.MustNewConstMetric(sourceRegionAvailable, prometheus.GaugeValue, boolToFloat(available[sr.Name]), c.region, sr.Name)
+	}
+
+	rs, err := c.client.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+
+	for _, r := range rs {
+		sourceRegion, ok := replicaSourceRegion(r.ReplicaSource)
+		if !ok || sourceRegion == c.region {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			crossRegionReplicaSupported, prometheus.GaugeValue, boolToFloat(available[sourceRegion]),
+			c.region, r.Identifier, sourceRegion, r.ReplicaSource,
+		)
+	}
+}
@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+func TestReplicaSourceRegion(t *testing.T) {
+	tests := []struct {
+		source string
+		region string
+		ok     bool
+	}{
+		{"", "", false},
+		{"db-1", "", false},
+		{"arn:aws:rds:us-west-2:123456789012:db:db-1", "us-west-2", true},
+		{"arn:broken", "", false},
+	}
+
+	for _, test := range tests {
+		region, ok := replicaSourceRegion(test.source)
+		if region != test.region || ok != test.ok {
+			t.Errorf("replicaSourceRegion(%q) = %q, %v, want %q, %v", test.source, region, ok, test.region, test.ok)
+		}
+	}
+}
+
+func TestSourceRegionsCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeSourceRegionsPages(t, mockRDS, false,
+		types.SourceRegion{Name: "us-west-2", Endpoint: "rds.us-west-2.amazonaws.com", Status: "available"},
+		types.SourceRegion{Name: "eu-west-1", Endpoint: "rds.eu-west-1.amazonaws.com", Status: "unavailable"},
+	)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false,
+		types.DBInstance{Identifier: "db-1"},
+		types.DBInstance{Identifier: "db-2", ReplicaSource: "db-1"},
+		types.DBInstance{Identifier: "db-3", ReplicaSource: "arn:aws:rds:us-west-2:123456789012:db:db-src"},
+		types.DBInstance{Identifier: "db-4", ReplicaSource: "arn:aws:rds:eu-west-1:123456789012:db:db-src"},
+		types.DBInstance{Identifier: "db-5", ReplicaSource: "arn:aws:rds:ap-south-1:123456789012:db:db-src"},
+	)
+
+	c := NewSourceRegionsCollector(&RDSClient{client: mockRDS}, "us-east-1", log.NewNopLogger())
+
+	want := `
+# HELP aws_rds_cross_region_replica_source_supported Whether the source region of the cross-region read replica is still listed as an available source region
+# TYPE aws_rds_cross_region_replica_source_supported gauge
+aws_rds_cross_region_replica_source_supported{instance="db-3",region="us-east-1",source="arn:aws:rds:us-west-2:123456789012:db:db-src",source_region="us-west-2"} 1
+aws_rds_cross_region_replica_source_supported{instance="db-4",region="us-east-1",source="arn:aws:rds:eu-west-1:123456789012:db:db-src",source_region="eu-west-1"} 0
+aws_rds_cross_region_replica_source_supported{instance="db-5",region="us-east-1",source="arn:aws:rds:ap-south-1:123456789012:db:db-src",source_region="ap-south-1"} 0
+# HELP aws_rds_source_region_available Whether the source region is available for replication
+# TYPE aws_rds_source_region_available gauge
+aws_rds_source_region_available{region="us-east-1",source_region="eu-west-1"} 0
+aws_rds_source_region_available{region="us-east-1",source_region="us-west-2"} 1
+# HELP aws_rds_source_region_info Region the configured region can create read replicas and copy snapshots from, with its status
+# TYPE aws_rds_source_region_info gauge
+aws_rds_source_region_info{region="us-east-1",source_region="eu-west-1",status="unavailable"} 1
+aws_rds_source_region_info{region="us-east-1",source_region="us-west-2",status="available"} 1
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
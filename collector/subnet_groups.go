@@ -0,0 +1,182 @@
+package collector
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+const subnetStatusActive = "Active"
+
+// Metrics descriptions
+var (
+	subnetGroupLabels = []string{"region", "subnet_group"}
+
+	subnetGroupInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "subnet_group", "info"),
+		"VPC and status of the DB subnet group",
+		[]string{"region", "subnet_group", "vpc", "status"},
+		nil,
+	)
+
+	subnetGroupSubnets = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "subnet_group", "subnets"),
+		"Number of subnets of the DB subnet group",
+		subnetGroupLabels,
+		nil,
+	)
+
+	subnetGroupAvailabilityZones = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "subnet_group", "availability_zones"),
+		"Number of distinct availability zones the subnets of the DB subnet group span",
+		subnetGroupLabels,
+		nil,
+	)
+
+	subnetGroupSubnetActive = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "subnet_group", "subnet_active"),
+		"Whether the subnet of the DB subnet group is Active (1) or not (0)",
+		[]string{"region", "subnet_group", "subnet", "availability_zone", "status"},
+		nil,
+	)
+
+	multiAZSubnetGroupMisconfigured = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "multi_az", "subnet_group_misconfigured"),
+		"Whether the subnet group of the Multi-AZ instance spans fewer than two availability zones or has a subnet that is not Active",
+		[]string{"region", "instance", "subnet_group"},
+		nil,
+	)
+)
+
+// SubnetGroupGatherer is the interface that implements the methods required to gather DB subnet group data
+type SubnetGroupGatherer interface {
+	RDSGatherer
+	GetRDSSubnetGroups() ([]*types.DBSubnetGroup, error)
+}
+
+// GetRDSSubnetGroups will get the DB subnet groups from the RDS API
+func (e *RDSClient) GetRDSSubnetGroups() ([]*types.DBSubnetGroup, error) {
+	sgs := []*types.DBSubnetGroup{}
+	params := &rds.DescribeDBSubnetGroupsInput{
+		MaxRecords: aws.Int64(e.apiMaxResults),
+	}
+
+	err := e.client.DescribeDBSubnetGroupsPages(params, func(page *rds.DescribeDBSubnetGroupsOutput, lastPage bool) bool {
+		for _, sg := range page.DBSubnetGroups {
+			group := &types.DBSubnetGroup{
+				Name:   aws.StringValue(sg.DBSubnetGroupName),
+				Status: aws.StringValue(sg.SubnetGroupStatus),
+				VpcId:  aws.StringValue(sg.VpcId),
+			}
+			for _, s := range sg.Subnets {
+				subnet := types.Subnet{
+					Identifier: aws.StringValue(s.SubnetIdentifier),
+					Status:     aws.StringValue(s.SubnetStatus),
+				}
+				if s.SubnetAvailabilityZone != nil {
+					subnet.AvailabilityZone = aws.StringValue(s.SubnetAvailabilityZone.Name)
+				}
+				group.Subnets = append(group.Subnets, subnet)
+			}
+			sgs = append(sgs, group)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sgs, nil
+}
+
+// subnetGroupHealthy reports whether the subnet group can host a Multi-AZ
+// instance: it spans at least two availability zones and all its subnets are Active
+func subnetGroupHealthy(sg *types.DBSubnetGroup) bool {
+	azs := map[string]bool{}
+	for _, s := range sg.Subnets {
+		if s.Status != subnetStatusActive {
+			return false
+		}
+		azs[s.AvailabilityZone] = true
+	}
+	return len(azs) >= 2
+}
+
+// NewSubnetGroupsCollector returns a collector for the DB subnet groups and
+// the Multi-AZ instances placed in them
+func NewSubnetGroupsCollector(client SubnetGroupGatherer, awsRegion string, logger log.Logger) *subnetGroupsCollector {
+	return &subnetGroupsCollector{
+		client: client,
+		region: awsRegion,
+		logger: logger,
+	}
+}
+
+type subnetGroupsCollector struct {
+	client SubnetGroupGatherer
+	region string
+	logger log.Logger
+}
+
+// Describe describes the metrics exported by the subnet groups collector. It
+// implements prometheus.Collector.
+func (c *subnetGroupsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- subnetGroupInfo
+	ch <- subnetGroupSubnets
+	ch <- subnetGroupAvailabilityZones
+	ch <- subnetGroupSubnetActive
+	ch <- multiAZSubnetGroupMisconfigured
+}
+
+// Collect fetches the DB subnet groups and delivers them as Prometheus
+// metrics. It implements prometheus.Collector
+func (c *subnetGroupsCollector) Collect(ch chan<- prometheus.Metric) {
+	sgs, err := c.client.GetRDSSubnetGroups()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS subnet groups", "err", err)
+		return
+	}
+
+	byName := map[string]*types.DBSubnetGroup{}
+	for _, sg := range sgs {
+		byName[sg.Name] = sg
+
+		azs := map[string]bool{}
+		for _, s := range sg.Subnets {
+			azs[s.AvailabilityZone] = true
+			ch <- prometheus.MustNewConstMetric(
+				subnetGroupSubnetActive, prometheus.GaugeValue, boolToFloat(s.Status == subnetStatusActive), c.region, sg.Name, s.Identifier, s.AvailabilityZone, s.Status,
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			subnetGroupInfo, prometheus.GaugeValue, 1, c.region, sg.Name, sg.VpcId, sg.Status,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			subnetGroupSubnets, prometheus.GaugeValue, float64(len(sg.Subnets)), c.region, sg.Name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			subnetGroupAvailabilityZones, prometheus.GaugeValue, float64(len(azs)), c.region, sg.Name,
+		)
+	}
+
+	rs, err := c.client.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+
+	for _, r := range rs {
+		sg, ok := byName[r.SubnetGroup]
+		if !r.MultiAZ || !ok {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			multiAZSubnetGroupMisconfigured, prometheus.GaugeValue, boolToFloat(!subnetGroupHealthy(sg)), c.region, r.Identifier, r.SubnetGroup,
+		)
+	}
+}
@@ -0,0 +1,61 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+func TestSubnetGroupsCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false,
+		types.DBInstance{Identifier: "db-1", MultiAZ: true, SubnetGroup: "two-azs"},
+		types.DBInstance{Identifier: "db-2", MultiAZ: true, SubnetGroup: "one-az"},
+		types.DBInstance{Identifier: "db-3", MultiAZ: false, SubnetGroup: "one-az"},
+	)
+	awsMock.MockDescribeDBSubnetGroupsPages(t, mockRDS, false,
+		types.DBSubnetGroup{Name: "two-azs", Status: "Complete", VpcId: "vpc-1", Subnets: []types.Subnet{
+			{Identifier: "subnet-a", AvailabilityZone: "us-east-1a", Status: "Active"},
+			{Identifier: "subnet-b", AvailabilityZone: "us-east-1b", Status: "Active"},
+		}},
+		types.DBSubnetGroup{Name: "one-az", Status: "Complete", VpcId: "vpc-1", Subnets: []types.Subnet{
+			{Identifier: "subnet-c", AvailabilityZone: "us-east-1a", Status: "Active"},
+			{Identifier: "subnet-d", AvailabilityZone: "us-east-1a", Status: "Active"},
+		}},
+	)
+
+	c := NewSubnetGroupsCollector(&RDSClient{client: mockRDS}, "us-east-1", log.NewNopLogger())
+
+	want := `
+# HELP aws_rds_multi_az_subnet_group_misconfigured Whether the subnet group of the Multi-AZ instance spans fewer than two availability zones or has a subnet that is not Active
+# TYPE aws_rds_multi_az_subnet_group_misconfigured gauge
+aws_rds_multi_az_subnet_group_misconfigured{instance="db-1",region="us-east-1",subnet_group="two-azs"} 0
+aws_rds_multi_az_subnet_group_misconfigured{instance="db-2",region="us-east-1",subnet_group="one-az"} 1
+# HELP aws_rds_subnet_group_availability_zones Number of distinct availability zones the subnets of the DB subnet group span
+# TYPE aws_rds_subnet_group_availability_zones gauge
+aws_rds_subnet_group_availability_zones{region="us-east-1",subnet_group="one-az"} 1
+aws_rds_subnet_group_availability_zones{region="us-east-1",subnet_group="two-azs"} 2
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "aws_rds_multi_az_subnet_group_misconfigured", "aws_rds_subnet_group_availability_zones"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSubnetGroupHealthy(t *testing.T) {
+	sg := &types.DBSubnetGroup{Subnets: []types.Subnet{
+		{Identifier: "subnet-a", AvailabilityZone: "us-east-1a", Status: "Active"},
+		{Identifier: "subnet-b", AvailabilityZone: "us-east-1b", Status: "Deleted"},
+	}}
+	if subnetGroupHealthy(sg) {
+		t.Errorf("Wanted a subnet group with a subnet that is not Active to be unhealthy")
+	}
+}
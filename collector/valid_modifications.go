@@ -0,0 +1,216 @@
+package collector
+
+import (
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+// Metrics descriptions
+var (
+	validModificationStorageLabels = []string{"region", "instance", "storage_type"}
+
+	validStorageMin = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "valid_modification", "storage_min_bytes"),
+		"Smallest storage in bytes the RDS instance can be modified to with the storage type",
+		validModificationStorageLabels,
+		nil,
+	)
+
+	validStorageMax = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "valid_modification", "storage_max_bytes"),
+		"Largest storage in bytes the RDS instance can be modified to with the storage type",
+		validModificationStorageLabels,
+		nil,
+	)
+
+	validIopsMin = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "valid_modification", "iops_min"),
+		"Lowest provisioned iops the RDS instance can be modified to with the storage type",
+		validModificationStorageLabels,
+		nil,
+	)
+
+	validIopsMax = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "valid_modification", "iops_max"),
+		"Highest provisioned iops the RDS instance can be modified to with the storage type",
+		validModificationStorageLabels,
+		nil,
+	)
+
+	validIopsRatioMin = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "valid_modification", "iops_to_storage_ratio_min"),
+		"Lowest ratio of provisioned iops to storage in GiB valid for the RDS instance with the storage type",
+		validModificationStorageLabels,
+		nil,
+	)
+
+	validIopsRatioMax = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "valid_modification", "iops_to_storage_ratio_max"),
+		"Highest ratio of provisioned iops to storage in GiB valid for the RDS instance with the storage type",
+		validModificationStorageLabels,
+		nil,
+	)
+
+	validInstanceClasses = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "valid_modification", "instance_classes"),
+		"Number of instance classes orderable for the engine version of the RDS instance",
+		labels,
+		nil,
+	)
+)
+
+// ValidModificationGatherer is the interface that implements the methods required to gather valid instance modifications
+type ValidModificationGatherer interface {
+	RDSGatherer
+	GetRDSValidStorageOptions(instance string) ([]*types.ValidStorageOptions, error)
+	GetRDSOrderableDBInstanceOptions(engine, version string) ([]*types.OrderableDBInstanceOption, error)
+}
+
+// GetRDSValidStorageOptions will get the storage the instance can be modified to from the RDS API
+func (e *RDSClient) GetRDSValidStorageOptions(instance string) ([]*types.ValidStorageOptions, error) {
+	params := &rds.DescribeValidDBInstanceModificationsInput{
+		DBInstanceIdentifier: aws.String(instance),
+	}
+
+	resp, err := e.client.DescribeValidDBInstanceModifications(params)
+	if err != nil {
+		return nil, err
+	}
+
+	vs := []*types.ValidStorageOptions{}
+	if resp.ValidDBInstanceModificationsMessage == nil {
+		return vs, nil
+	}
+	for _, s := range resp.ValidDBInstanceModificationsMessage.Storage {
+		v := &types.ValidStorageOptions{
+			StorageType: aws.StringValue(s.StorageType),
+		}
+		for _, r := range s.StorageSize {
+			// multiply by 10^9, so that it returns bytes (prometheus standard)
+			v.StorageSize = append(v.StorageSize, types.Range{
+				From: float64(aws.Int64Value(r.From)) * math.Pow(10, 9),
+				To:   float64(aws.Int64Value(r.To)) * math.Pow(10, 9),
+			})
+		}
+		for _, r := range s.ProvisionedIops {
+			v.ProvisionedIops = append(v.ProvisionedIops, types.Range{
+				From: float64(aws.Int64Value(r.From)),
+				To:   float64(aws.Int64Value(r.To)),
+			})
+		}
+		for _, r := range s.IopsToStorageRatio {
+			v.IopsToStorageRatio = append(v.IopsToStorageRatio, types.Range{
+				From: aws.Float64Value(r.From),
+				To:   aws.Float64Value(r.To),
+			})
+		}
+		vs = append(vs, v)
+	}
+
+	return vs, nil
+}
+
+// bounds returns the lowest lower bound and the highest upper bound of the ranges
+func bounds(rs []types.Range) (float64, float64) {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, r := range rs {
+		min = math.Min(min, r.From)
+		max = math.Max(max, r.To)
+	}
+	return min, max
+}
+
+// NewValidModificationsCollector returns a collector for the storage and
+// instance class modifications valid for the RDS instances. Results are
+// cached for cacheTTL, since they change rarely and are fetched per instance.
+func NewValidModificationsCollector(client ValidModificationGatherer, awsRegion string, cacheTTL time.Duration, logger log.Logger) *validModificationsCollector {
+	return &validModificationsCollector{
+		client: client,
+		region: awsRegion,
+		cache:  newTTLCache(cacheTTL),
+		logger: logger,
+	}
+}
+
+type validModificationsCollector struct {
+	client ValidModificationGatherer
+	region string
+	cache  *ttlCache
+	logger log.Logger
+}
+
+// Describe describes the metrics exported by the valid modifications
+// collector. It implements prometheus.Collector.
+func (c *validModificationsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- validStorageMin
+	ch <- validStorageMax
+	ch <- validIopsMin
+	ch <- validIopsMax
+	ch <- validIopsRatioMin
+	ch <- validIopsRatioMax
+	ch <- validInstanceClasses
+}
+
+// Collect fetches the valid modifications of every RDS instance and
+// delivers them as Prometheus metrics. It implements prometheus.Collector
+func (c *validModificationsCollector) Collect(ch chan<- prometheus.Metric) {
+	rs, err := c.client.GetRDSInstances()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "Error getting RDS instances", "err", err)
+		return
+	}
+
+	for _, r := range rs {
+		v, err := c.cache.get("storage/"+r.Identifier, func() (interface{}, error) {
+			return c.client.GetRDSValidStorageOptions(r.Identifier)
+		})
+		if err != nil {
+			level.Error(c.logger).Log("msg", "Error getting RDS valid modifications", "instance", r.Identifier, "err", err)
+			continue
+		}
+
+		for _, s := range v.([]*types.ValidStorageOptions) {
+			if len(s.StorageSize) > 0 {
+				min, max := bounds(s.StorageSize)
+				ch <- prometheus.MustNewConstMetric(validStorageMin, prometheus.GaugeValue, min, c.region, r.Identifier, s.StorageType)
+				ch <- prometheus.MustNewConstMetric(validStorageMax, prometheus.GaugeValue, max, c.region, r.Identifier, s.StorageType)
+			}
+			if len(s.ProvisionedIops) > 0 {
+				min, max := bounds(s.ProvisionedIops)
+				ch <- prometheus.MustNewConstMetric(validIopsMin, prometheus.GaugeValue, min, c.region, r.Identifier, s.StorageType)
+				ch <- prometheus.MustNewConstMetric(validIopsMax, prometheus.GaugeValue, max, c.region, r.Identifier, s.StorageType)
+			}
+			if len(s.IopsToStorageRatio) > 0 {
+				min, max := bounds(s.IopsToStorageRatio)
+				ch <- prometheus.MustNewConstMetric(validIopsRatioMin, prometheus.GaugeValue, min, c.region, r.Identifier, s.StorageType)
+				ch <- prometheus.MustNewConstMetric(validIopsRatioMax, prometheus.GaugeValue, max, c.region, r.Identifier, s.StorageType)
+			}
+		}
+
+		// DescribeValidDBInstanceModifications does not list instance
+		// classes, the classes orderable for the engine version are
+		// what the instance can be modified to
+		v, err = c.cache.get("orderable/"+r.Engine+"/"+r.EngineVersion, func() (interface{}, error) {
+			return c.client.GetRDSOrderableDBInstanceOptions(r.Engine, r.EngineVersion)
+		})
+		if err != nil {
+			level.Error(c.logger).Log("msg", "Error getting RDS orderable instance options", "instance", r.Identifier, "err", err)
+			continue
+		}
+		classes := map[string]bool{}
+		for _, o := range v.([]*types.OrderableDBInstanceOption) {
+			classes[o.Class] = true
+		}
+		ch <- prometheus.MustNewConstMetric(
+			validInstanceClasses, prometheus.GaugeValue, float64(len(classes)), c.region, r.Identifier,
+		)
+	}
+}
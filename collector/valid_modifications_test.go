@@ -0,0 +1,60 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	awsMock "github.com/alecrajeev/aws_rds_exporter/mock/aws"
+	"github.com/alecrajeev/aws_rds_exporter/mock/aws/sdk"
+	"github.com/alecrajeev/aws_rds_exporter/types"
+)
+
+func TestValidModificationsCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRDS := sdk.NewMockRDSAPI(ctrl)
+	awsMock.MockDescribeDBInstances(t, mockRDS, false, types.DBInstance{Identifier: "db-1", Engine: "mysql", EngineVersion: "8.0.21"})
+	awsMock.MockDescribeValidDBInstanceModifications(t, mockRDS, false,
+		types.ValidStorageOptions{StorageType: "gp2", StorageSize: []types.Range{{From: 20, To: 65536}}},
+		types.ValidStorageOptions{
+			StorageType:        "io1",
+			StorageSize:        []types.Range{{From: 100, To: 16384}, {From: 16385, To: 65536}},
+			ProvisionedIops:    []types.Range{{From: 1000, To: 80000}},
+			IopsToStorageRatio: []types.Range{{From: 1, To: 50}},
+		},
+	)
+	awsMock.MockDescribeOrderableDBInstanceOptionsPages(t, mockRDS, false,
+		types.OrderableDBInstanceOption{Class: "db.m5.large", Engine: "mysql", EngineVersion: "8.0.21", StorageType: "gp2"},
+		types.OrderableDBInstanceOption{Class: "db.m5.large", Engine: "mysql", EngineVersion: "8.0.21", StorageType: "io1"},
+		types.OrderableDBInstanceOption{Class: "db.r5.large", Engine: "mysql", EngineVersion: "8.0.21", StorageType: "gp2"},
+	)
+
+	c := NewValidModificationsCollector(&RDSClient{client: mockRDS}, "us-east-1", time.Hour, log.NewNopLogger())
+
+	want := `
+# HELP aws_rds_valid_modification_instance_classes Number of instance classes orderable for the engine version of the RDS instance
+# TYPE aws_rds_valid_modification_instance_classes gauge
+aws_rds_valid_modification_instance_classes{instance="db-1",region="us-east-1"} 2
+# HELP aws_rds_valid_modification_iops_max Highest provisioned iops the RDS instance can be modified to with the storage type
+# TYPE aws_rds_valid_modification_iops_max gauge
+aws_rds_valid_modification_iops_max{instance="db-1",region="us-east-1",storage_type="io1"} 80000
+# HELP aws_rds_valid_modification_storage_max_bytes Largest storage in bytes the RDS instance can be modified to with the storage type
+# TYPE aws_rds_valid_modification_storage_max_bytes gauge
+aws_rds_valid_modification_storage_max_bytes{instance="db-1",region="us-east-1",storage_type="gp2"} 6.5536e+13
+aws_rds_valid_modification_storage_max_bytes{instance="db-1",region="us-east-1",storage_type="io1"} 6.5536e+13
+# HELP aws_rds_valid_modification_storage_min_bytes Smallest storage in bytes the RDS instance can be modified to with the storage type
+# TYPE aws_rds_valid_modification_storage_min_bytes gauge
+aws_rds_valid_modification_storage_min_bytes{instance="db-1",region="us-east-1",storage_type="gp2"} 2e+10
+aws_rds_valid_modification_storage_min_bytes{instance="db-1",region="us-east-1",storage_type="io1"} 1e+11
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want),
+		"aws_rds_valid_modification_instance_classes", "aws_rds_valid_modification_iops_max",
+		"aws_rds_valid_modification_storage_max_bytes", "aws_rds_valid_modification_storage_min_bytes"); err != nil {
+		t.Error(err)
+	}
+}
@@ -22,23 +22,682 @@ func MockDescribeDBInstances(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantErro
 
 	for _, instance := range testInstances {
 
-		b := int64(instance.AllocatedStorage)
 		c := int64(instance.Iops)
 
+		pgs := []*rds.DBParameterGroupStatus{}
+		for _, pg := range instance.ParameterGroups {
+			pgs = append(pgs, &rds.DBParameterGroupStatus{DBParameterGroupName: aws.String(pg)})
+		}
+
 		rdsInstance := &rds.DBInstance{
-			AllocatedStorage:     &b,
-			DBInstanceIdentifier: aws.String(instance.Identifier),
-			Iops:                 &c,
+			DBInstanceIdentifier:       aws.String(instance.Identifier),
+			Iops:                       &c,
+			DBInstanceClass:            aws.String(instance.Class),
+			Engine:                     aws.String(instance.Engine),
+			EngineVersion:              aws.String(instance.EngineVersion),
+			DBParameterGroups:          pgs,
+			MultiAZ:                    aws.Bool(instance.MultiAZ),
+			StorageType:                aws.String(instance.StorageType),
+			LicenseModel:               aws.String(instance.LicenseModel),
+			DbiResourceId:              aws.String(instance.ResourceID),
+			MonitoringInterval:         aws.Int64(int64(instance.MonitoringInterval)),
+			PerformanceInsightsEnabled: aws.Bool(instance.PerformanceInsights),
+			DBInstanceArn:              aws.String(instance.Arn),
+		}
+		if instance.Cluster != "" {
+			rdsInstance.DBClusterIdentifier = aws.String(instance.Cluster)
+		}
+		for k, v := range instance.Tags {
+			rdsInstance.TagList = append(rdsInstance.TagList, &rds.Tag{Key: aws.String(k), Value: aws.String(v)})
+		}
+		if instance.KmsKeyID != "" {
+			rdsInstance.KmsKeyId = aws.String(instance.KmsKeyID)
+		}
+		if instance.PerformanceInsightsKmsKeyID != "" {
+			rdsInstance.PerformanceInsightsKMSKeyId = aws.String(instance.PerformanceInsightsKmsKeyID)
+		}
+		// DocumentDB and Neptune instances may come without AllocatedStorage
+		if instance.AllocatedStorage != 0 {
+			rdsInstance.AllocatedStorage = aws.Int64(int64(instance.AllocatedStorage))
+		}
+		if instance.ReplicaSource != "" {
+			rdsInstance.ReadReplicaSourceDBInstanceIdentifier = aws.String(instance.ReplicaSource)
+		}
+		if instance.SubnetGroup != "" {
+			rdsInstance.DBSubnetGroup = &rds.DBSubnetGroup{DBSubnetGroupName: aws.String(instance.SubnetGroup)}
 		}
 
 		rIds = append(rIds, rdsInstance)
 	}
 
+	// builds mock output based on the input, honouring the engine filter
+	mockMatcher.EXPECT().DescribeDBInstances(gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeDBInstancesInput) (*rds.DescribeDBInstancesOutput, error) {
+			engines := []string{}
+			for _, f := range input.Filters {
+				if aws.StringValue(f.Name) == "engine" {
+					engines = aws.StringValueSlice(f.Values)
+				}
+			}
+			result := &rds.DescribeDBInstancesOutput{DBInstances: []*rds.DBInstance{}}
+			for _, r := range rIds {
+				if len(engines) > 0 && !containsEngine(engines, aws.StringValue(r.Engine)) {
+					continue
+				}
+				result.DBInstances = append(result.DBInstances, r)
+			}
+			return result, err
+		}).AnyTimes()
+
+}
+
+func containsEngine(engines []string, engine string) bool {
+	for _, e := range engines {
+		if e == engine {
+			return true
+		}
+	}
+	return false
+}
+
+// MockDescribeEventsPages mocks describing the RDS events
+func MockDescribeEventsPages(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testEvents ...types.DBEvent) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeEventsPages wrong!")
+	}
+	evs := []*(rds.Event){}
+
+	for _, event := range testEvents {
+		evs = append(evs, &rds.Event{
+			SourceIdentifier: aws.String(event.SourceIdentifier),
+			SourceType:       aws.String(event.SourceType),
+			EventCategories:  aws.StringSlice(event.Categories),
+			Date:             aws.Time(event.Date),
+			Message:          aws.String(event.Message),
+		})
+	}
+
+	// builds mock output based on the input
+	result := &rds.DescribeEventsOutput{
+		Events: evs,
+	}
+	mockMatcher.EXPECT().DescribeEventsPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeEventsInput, fn func(*rds.DescribeEventsOutput, bool) bool) error {
+			if err == nil {
+				fn(result, true)
+			}
+			return err
+		}).AnyTimes()
+}
+
+// MockDescribeDBClustersPages mocks describing the RDS Clusters
+func MockDescribeDBClustersPages(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testClusters ...types.DBCluster) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeDBClustersPages wrong!")
+	}
+	cs := []*(rds.DBCluster){}
+
+	for _, cluster := range testClusters {
+		cs = append(cs, &rds.DBCluster{
+			DBClusterIdentifier: aws.String(cluster.Identifier),
+			Engine:              aws.String(cluster.Engine),
+			DBClusterArn:        aws.String(cluster.Arn),
+			KmsKeyId:            aws.String(cluster.KmsKeyID),
+		})
+	}
+
+	// builds mock output based on the input
+	result := &rds.DescribeDBClustersOutput{
+		DBClusters: cs,
+	}
+	mockMatcher.EXPECT().DescribeDBClustersPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeDBClustersInput, fn func(*rds.DescribeDBClustersOutput, bool) bool) error {
+			if err == nil {
+				fn(result, true)
+			}
+			return err
+		}).AnyTimes()
+}
+
+// MockDescribeEventSubscriptionsPages mocks describing the RDS event subscriptions
+func MockDescribeEventSubscriptionsPages(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testSubscriptions ...types.EventSubscription) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeEventSubscriptionsPages wrong!")
+	}
+	subs := []*(rds.EventSubscription){}
+
+	for _, sub := range testSubscriptions {
+		subs = append(subs, &rds.EventSubscription{
+			CustSubscriptionId:  aws.String(sub.Name),
+			Status:              aws.String(sub.Status),
+			Enabled:             aws.Bool(sub.Enabled),
+			SourceType:          aws.String(sub.SourceType),
+			EventCategoriesList: aws.StringSlice(sub.Categories),
+			SourceIdsList:       aws.StringSlice(sub.SourceIds),
+			SnsTopicArn:         aws.String(sub.SnsTopicArn),
+		})
+	}
+
+	// builds mock output based on the input
+	result := &rds.DescribeEventSubscriptionsOutput{
+		EventSubscriptionsList: subs,
+	}
+	mockMatcher.EXPECT().DescribeEventSubscriptionsPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeEventSubscriptionsInput, fn func(*rds.DescribeEventSubscriptionsOutput, bool) bool) error {
+			if err == nil {
+				fn(result, true)
+			}
+			return err
+		}).AnyTimes()
+}
+
+// MockDescribeDBProxyTargetsPages mocks describing the targets of an RDS Proxy target group
+func MockDescribeDBProxyTargetsPages(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testTargets ...types.DBProxyTarget) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeDBProxyTargetsPages wrong!")
+	}
+	ts := []*(rds.DBProxyTarget){}
+
+	for _, target := range testTargets {
+		ts = append(ts, &rds.DBProxyTarget{
+			RdsResourceId: aws.String(target.RdsResourceId),
+			Type:          aws.String(target.Type),
+			TargetHealth: &rds.TargetHealth{
+				State:  aws.String(target.State),
+				Reason: aws.String(target.Reason),
+			},
+		})
+	}
+
+	// builds mock output based on the input
+	result := &rds.DescribeDBProxyTargetsOutput{
+		Targets: ts,
+	}
+	mockMatcher.EXPECT().DescribeDBProxyTargetsPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeDBProxyTargetsInput, fn func(*rds.DescribeDBProxyTargetsOutput, bool) bool) error {
+			if err == nil {
+				fn(result, true)
+			}
+			return err
+		}).AnyTimes()
+}
+
+// MockDescribeDBEngineVersionsPages mocks describing the RDS engine version catalog
+func MockDescribeDBEngineVersionsPages(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testVersions ...types.DBEngineVersion) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeDBEngineVersionsPages wrong!")
+	}
+	vs := []*(rds.DBEngineVersion){}
+
+	for _, version := range testVersions {
+		targets := []*rds.UpgradeTarget{}
+		for _, target := range version.UpgradeTargets {
+			targets = append(targets, &rds.UpgradeTarget{
+				Engine:                aws.String(version.Engine),
+				EngineVersion:         aws.String(target.Version),
+				IsMajorVersionUpgrade: aws.Bool(target.IsMajorVersionUpgrade),
+			})
+		}
+		vs = append(vs, &rds.DBEngineVersion{
+			Engine:                 aws.String(version.Engine),
+			EngineVersion:          aws.String(version.Version),
+			DBParameterGroupFamily: aws.String(version.Family),
+			Status:                 aws.String(version.Status),
+			ValidUpgradeTarget:     targets,
+		})
+	}
+
+	// builds mock output based on the input
+	result := &rds.DescribeDBEngineVersionsOutput{
+		DBEngineVersions: vs,
+	}
+	mockMatcher.EXPECT().DescribeDBEngineVersionsPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeDBEngineVersionsInput, fn func(*rds.DescribeDBEngineVersionsOutput, bool) bool) error {
+			if err == nil {
+				fn(result, true)
+			}
+			return err
+		}).AnyTimes()
+}
+
+// MockDescribeDBParameterGroupsPages mocks describing the DB parameter groups
+func MockDescribeDBParameterGroupsPages(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testGroups ...types.DBParameterGroup) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeDBParameterGroupsPages wrong!")
+	}
+	pgs := []*(rds.DBParameterGroup){}
+
+	for _, group := range testGroups {
+		pgs = append(pgs, &rds.DBParameterGroup{
+			DBParameterGroupName:   aws.String(group.Name),
+			DBParameterGroupFamily: aws.String(group.Family),
+		})
+	}
+
+	// builds mock output based on the input
+	result := &rds.DescribeDBParameterGroupsOutput{
+		DBParameterGroups: pgs,
+	}
+	mockMatcher.EXPECT().DescribeDBParameterGroupsPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeDBParameterGroupsInput, fn func(*rds.DescribeDBParameterGroupsOutput, bool) bool) error {
+			if err == nil {
+				fn(result, true)
+			}
+			return err
+		}).AnyTimes()
+}
+
+// MockDescribeDBParametersPages mocks describing the parameters of a DB parameter group.
+// Only the parameters matching the requested source are returned.
+func MockDescribeDBParametersPages(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testParameters ...types.DBParameter) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeDBParametersPages wrong!")
+	}
+
+	mockMatcher.EXPECT().DescribeDBParametersPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeDBParametersInput, fn func(*rds.DescribeDBParametersOutput, bool) bool) error {
+			if err != nil {
+				return err
+			}
+			ps := []*(rds.Parameter){}
+			for _, p := range testParameters {
+				if input.Source != nil && aws.StringValue(input.Source) != p.Source {
+					continue
+				}
+				ps = append(ps, toParameter(p))
+			}
+			fn(&rds.DescribeDBParametersOutput{Parameters: ps}, true)
+			return nil
+		}).AnyTimes()
+}
+
+// MockDescribeEngineDefaultParametersPages mocks describing the engine default parameters of a family
+func MockDescribeEngineDefaultParametersPages(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testParameters ...types.DBParameter) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeEngineDefaultParametersPages wrong!")
+	}
+	ps := []*(rds.Parameter){}
+
+	for _, p := range testParameters {
+		ps = append(ps, toParameter(p))
+	}
+
+	// builds mock output based on the input
+	result := &rds.DescribeEngineDefaultParametersOutput{
+		EngineDefaults: &rds.EngineDefaults{Parameters: ps},
+	}
+	mockMatcher.EXPECT().DescribeEngineDefaultParametersPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeEngineDefaultParametersInput, fn func(*rds.DescribeEngineDefaultParametersOutput, bool) bool) error {
+			if err == nil {
+				fn(result, true)
+			}
+			return err
+		}).AnyTimes()
+}
+
+func toParameter(p types.DBParameter) *rds.Parameter {
+	return &rds.Parameter{
+		ParameterName:  aws.String(p.Name),
+		ParameterValue: aws.String(p.Value),
+		Source:         aws.String(p.Source),
+		DataType:       aws.String(p.DataType),
+	}
+}
+
+// MockDescribeDBLogFilesPages mocks describing the log files of an RDS instance
+func MockDescribeDBLogFilesPages(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testFiles ...types.DBLogFile) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeDBLogFilesPages wrong!")
+	}
+	fs := []*(rds.DescribeDBLogFilesDetails){}
+
+	for _, file := range testFiles {
+		fs = append(fs, &rds.DescribeDBLogFilesDetails{
+			LogFileName: aws.String(file.Name),
+			Size:        aws.Int64(int64(file.Size)),
+			LastWritten: aws.Int64(aws.TimeUnixMilli(file.LastWritten)),
+		})
+	}
+
 	// builds mock output based on the input
-	result := &rds.DescribeDBInstancesOutput{
-		DBInstances: rIds,
+	result := &rds.DescribeDBLogFilesOutput{
+		DescribeDBLogFiles: fs,
 	}
-	mockMatcher.EXPECT().DescribeDBInstances(gomock.Any()).Do(func(input interface{}) {
-	}).AnyTimes().Return(result, err)
+	mockMatcher.EXPECT().DescribeDBLogFilesPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeDBLogFilesInput, fn func(*rds.DescribeDBLogFilesOutput, bool) bool) error {
+			if err == nil {
+				fn(result, true)
+			}
+			return err
+		}).AnyTimes()
+}
+
+// MockDownloadDBLogFilePortion mocks downloading log file portions, one portion per call in order.
+// Once all portions have been returned, empty portions with the last marker are returned.
+func MockDownloadDBLogFilePortion(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testPortions ...types.DBLogFilePortion) {
+	var err error
+	if wantError {
+		err = errors.New("DownloadDBLogFilePortion wrong!")
+	}
+
+	calls := 0
+	mockMatcher.EXPECT().DownloadDBLogFilePortion(gomock.Any()).DoAndReturn(
+		func(input *rds.DownloadDBLogFilePortionInput) (*rds.DownloadDBLogFilePortionOutput, error) {
+			if err != nil {
+				return nil, err
+			}
+			if calls >= len(testPortions) {
+				return &rds.DownloadDBLogFilePortionOutput{
+					LogFileData:           aws.String(""),
+					Marker:                input.Marker,
+					AdditionalDataPending: aws.Bool(false),
+				}, nil
+			}
+			p := testPortions[calls]
+			calls++
+			return &rds.DownloadDBLogFilePortionOutput{
+				LogFileData:           aws.String(p.Data),
+				Marker:                aws.String(p.Marker),
+				AdditionalDataPending: aws.Bool(p.AdditionalDataPending),
+			}, nil
+		}).AnyTimes()
+}
+
+// MockDescribeExportTasksPages mocks describing the snapshot export tasks
+func MockDescribeExportTasksPages(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testTasks ...types.ExportTask) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeExportTasksPages wrong!")
+	}
+	ts := []*(rds.ExportTask){}
+
+	for _, task := range testTasks {
+		et := &rds.ExportTask{
+			ExportTaskIdentifier:   aws.String(task.Identifier),
+			SourceArn:              aws.String(task.SourceArn),
+			S3Bucket:               aws.String(task.S3Bucket),
+			Status:                 aws.String(task.Status),
+			PercentProgress:        aws.Int64(int64(task.PercentProgress)),
+			TotalExtractedDataInGB: aws.Int64(int64(task.TotalExtractedData)),
+			FailureCause:           aws.String(task.FailureCause),
+		}
+		if !task.StartTime.IsZero() {
+			et.TaskStartTime = aws.Time(task.StartTime)
+		}
+		if !task.EndTime.IsZero() {
+			et.TaskEndTime = aws.Time(task.EndTime)
+		}
+		ts = append(ts, et)
+	}
+
+	// builds mock output based on the input
+	result := &rds.DescribeExportTasksOutput{
+		ExportTasks: ts,
+	}
+	mockMatcher.EXPECT().DescribeExportTasksPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeExportTasksInput, fn func(*rds.DescribeExportTasksOutput, bool) bool) error {
+			if err == nil {
+				fn(result, true)
+			}
+			return err
+		}).AnyTimes()
+}
+
+// MockDescribeDBSubnetGroupsPages mocks describing the DB subnet groups
+func MockDescribeDBSubnetGroupsPages(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testGroups ...types.DBSubnetGroup) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeDBSubnetGroupsPages wrong!")
+	}
+	sgs := []*(rds.DBSubnetGroup){}
+
+	for _, group := range testGroups {
+		subnets := []*rds.Subnet{}
+		for _, subnet := range group.Subnets {
+			subnets = append(subnets, &rds.Subnet{
+				SubnetIdentifier:       aws.String(subnet.Identifier),
+				SubnetAvailabilityZone: &rds.AvailabilityZone{Name: aws.String(subnet.AvailabilityZone)},
+				SubnetStatus:           aws.String(subnet.Status),
+			})
+		}
+		sgs = append(sgs, &rds.DBSubnetGroup{
+			DBSubnetGroupName: aws.String(group.Name),
+			SubnetGroupStatus: aws.String(group.Status),
+			VpcId:             aws.String(group.VpcId),
+			Subnets:           subnets,
+		})
+	}
+
+	// builds mock output based on the input
+	result := &rds.DescribeDBSubnetGroupsOutput{
+		DBSubnetGroups: sgs,
+	}
+	mockMatcher.EXPECT().DescribeDBSubnetGroupsPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeDBSubnetGroupsInput, fn func(*rds.DescribeDBSubnetGroupsOutput, bool) bool) error {
+			if err == nil {
+				fn(result, true)
+			}
+			return err
+		}).AnyTimes()
+}
+
+// MockDescribeValidDBInstanceModifications mocks describing the valid modifications of an RDS instance
+func MockDescribeValidDBInstanceModifications(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testOptions ...types.ValidStorageOptions) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeValidDBInstanceModifications wrong!")
+	}
+	storage := []*(rds.ValidStorageOptions){}
+
+	for _, o := range testOptions {
+		s := &rds.ValidStorageOptions{StorageType: aws.String(o.StorageType)}
+		for _, r := range o.StorageSize {
+			s.StorageSize = append(s.StorageSize, &rds.Range{From: aws.Int64(int64(r.From)), To: aws.Int64(int64(r.To))})
+		}
+		for _, r := range o.ProvisionedIops {
+			s.ProvisionedIops = append(s.ProvisionedIops, &rds.Range{From: aws.Int64(int64(r.From)), To: aws.Int64(int64(r.To))})
+		}
+		for _, r := range o.IopsToStorageRatio {
+			s.IopsToStorageRatio = append(s.IopsToStorageRatio, &rds.DoubleRange{From: aws.Float64(r.From), To: aws.Float64(r.To)})
+		}
+		storage = append(storage, s)
+	}
+
+	// builds mock output based on the input
+	result := &rds.DescribeValidDBInstanceModificationsOutput{
+		ValidDBInstanceModificationsMessage: &rds.ValidDBInstanceModificationsMessage{Storage: storage},
+	}
+	mockMatcher.EXPECT().DescribeValidDBInstanceModifications(gomock.Any()).AnyTimes().Return(result, err)
+}
 
+// MockDescribeOrderableDBInstanceOptionsPages mocks describing the orderable instance options
+func MockDescribeOrderableDBInstanceOptionsPages(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testOptions ...types.OrderableDBInstanceOption) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeOrderableDBInstanceOptionsPages wrong!")
+	}
+	os := []*(rds.OrderableDBInstanceOption){}
+
+	for _, o := range testOptions {
+		os = append(os, &rds.OrderableDBInstanceOption{
+			DBInstanceClass: aws.String(o.Class),
+			Engine:          aws.String(o.Engine),
+			EngineVersion:   aws.String(o.EngineVersion),
+			LicenseModel:    aws.String(o.LicenseModel),
+			StorageType:     aws.String(o.StorageType),
+		})
+	}
+
+	// builds mock output based on the input
+	result := &rds.DescribeOrderableDBInstanceOptionsOutput{
+		OrderableDBInstanceOptions: os,
+	}
+	mockMatcher.EXPECT().DescribeOrderableDBInstanceOptionsPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeOrderableDBInstanceOptionsInput, fn func(*rds.DescribeOrderableDBInstanceOptionsOutput, bool) bool) error {
+			if err == nil {
+				fn(result, true)
+			}
+			return err
+		}).AnyTimes()
+}
+
+// MockDescribeSourceRegionsPages mocks describing the source regions
+func MockDescribeSourceRegionsPages(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testRegions ...types.SourceRegion) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeSourceRegionsPages wrong!")
+	}
+	srs := []*(rds.SourceRegion){}
+
+	for _, region := range testRegions {
+		srs = append(srs, &rds.SourceRegion{
+			RegionName: aws.String(region.Name),
+			Endpoint:   aws.String(region.Endpoint),
+			Status:     aws.String(region.Status),
+		})
+	}
+
+	// builds mock output based on the input
+	result := &rds.DescribeSourceRegionsOutput{
+		SourceRegions: srs,
+	}
+	mockMatcher.EXPECT().DescribeSourceRegionsPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeSourceRegionsInput, fn func(*rds.DescribeSourceRegionsOutput, bool) bool) error {
+			if err == nil {
+				fn(result, true)
+			}
+			return err
+		}).AnyTimes()
+}
+
+// MockDescribeReservedDBInstancesOfferingsPages mocks describing the reserved DB instance offerings, filtered by class, product and deployment
+func MockDescribeReservedDBInstancesOfferingsPages(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testOfferings ...types.ReservedDBInstancesOffering) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeReservedDBInstancesOfferingsPages wrong!")
+	}
+
+	// builds mock output based on the input
+	mockMatcher.EXPECT().DescribeReservedDBInstancesOfferingsPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeReservedDBInstancesOfferingsInput, fn func(*rds.DescribeReservedDBInstancesOfferingsOutput, bool) bool) error {
+			if err != nil {
+				return err
+			}
+			os := []*(rds.ReservedDBInstancesOffering){}
+			for _, o := range testOfferings {
+				if input.DBInstanceClass != nil && aws.StringValue(input.DBInstanceClass) != o.Class {
+					continue
+				}
+				if input.ProductDescription != nil && aws.StringValue(input.ProductDescription) != o.ProductDescription {
+					continue
+				}
+				if input.MultiAZ != nil && aws.BoolValue(input.MultiAZ) != o.MultiAZ {
+					continue
+				}
+				os = append(os, &rds.ReservedDBInstancesOffering{
+					ReservedDBInstancesOfferingId: aws.String(o.ID),
+					DBInstanceClass:               aws.String(o.Class),
+					ProductDescription:            aws.String(o.ProductDescription),
+					MultiAZ:                       aws.Bool(o.MultiAZ),
+					OfferingType:                  aws.String(o.OfferingType),
+					Duration:                      aws.Int64(int64(o.Duration)),
+					FixedPrice:                    aws.Float64(o.FixedPrice),
+					UsagePrice:                    aws.Float64(o.UsagePrice),
+					RecurringCharges: []*rds.RecurringCharge{{
+						RecurringChargeAmount:    aws.Float64(o.RecurringHourly),
+						RecurringChargeFrequency: aws.String("Hourly"),
+					}},
+				})
+			}
+			fn(&rds.DescribeReservedDBInstancesOfferingsOutput{ReservedDBInstancesOfferings: os}, true)
+			return nil
+		}).AnyTimes()
+}
+
+// MockDescribeReservedDBInstancesPages mocks describing the purchased reserved DB instances
+func MockDescribeReservedDBInstancesPages(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testReservations ...types.ReservedDBInstance) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeReservedDBInstancesPages wrong!")
+	}
+	rs := []*(rds.ReservedDBInstance){}
+
+	for _, r := range testReservations {
+		rs = append(rs, &rds.ReservedDBInstance{
+			ReservedDBInstanceId: aws.String(r.ID),
+			DBInstanceClass:      aws.String(r.Class),
+			ProductDescription:   aws.String(r.ProductDescription),
+			MultiAZ:              aws.Bool(r.MultiAZ),
+			DBInstanceCount:      aws.Int64(int64(r.Count)),
+			State:                aws.String(r.State),
+		})
+	}
+
+	// builds mock output based on the input
+	result := &rds.DescribeReservedDBInstancesOutput{
+		ReservedDBInstances: rs,
+	}
+	mockMatcher.EXPECT().DescribeReservedDBInstancesPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeReservedDBInstancesInput, fn func(*rds.DescribeReservedDBInstancesOutput, bool) bool) error {
+			if err == nil {
+				fn(result, true)
+			}
+			return err
+		}).AnyTimes()
+}
+
+// MockDescribeDBSnapshotsPages mocks describing the RDS instance and cluster snapshots
+func MockDescribeDBSnapshotsPages(t *testing.T, mockMatcher *sdk.MockRDSAPI, wantError bool, testSnapshots ...types.DBSnapshot) {
+	var err error
+	if wantError {
+		err = errors.New("DescribeDBSnapshotsPages wrong!")
+	}
+	ss := []*(rds.DBSnapshot){}
+	cs := []*(rds.DBClusterSnapshot){}
+
+	for _, snapshot := range testSnapshots {
+		if snapshot.Cluster {
+			cs = append(cs, &rds.DBClusterSnapshot{
+				DBClusterSnapshotIdentifier: aws.String(snapshot.Identifier),
+				KmsKeyId:                    aws.String(snapshot.KmsKeyID),
+			})
+			continue
+		}
+		ss = append(ss, &rds.DBSnapshot{
+			DBSnapshotIdentifier: aws.String(snapshot.Identifier),
+			KmsKeyId:             aws.String(snapshot.KmsKeyID),
+		})
+	}
+
+	// builds mock output based on the input
+	result := &rds.DescribeDBSnapshotsOutput{
+		DBSnapshots: ss,
+	}
+	mockMatcher.EXPECT().DescribeDBSnapshotsPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeDBSnapshotsInput, fn func(*rds.DescribeDBSnapshotsOutput, bool) bool) error {
+			if err == nil {
+				fn(result, true)
+			}
+			return err
+		}).AnyTimes()
+
+	clusterResult := &rds.DescribeDBClusterSnapshotsOutput{
+		DBClusterSnapshots: cs,
+	}
+	mockMatcher.EXPECT().DescribeDBClusterSnapshotsPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *rds.DescribeDBClusterSnapshotsInput, fn func(*rds.DescribeDBClusterSnapshotsOutput, bool) bool) error {
+			if err == nil {
+				fn(clusterResult, true)
+			}
+			return err
+		}).AnyTimes()
 }
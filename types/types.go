@@ -1,8 +1,278 @@
 package types
 
+import "time"
+
 // DBInstance represents a particular RDS instance
 type DBInstance struct {
-	Identifier       string  // Instance Identifier
-	AllocatedStorage float64 // allocated storage
-	Iops             float64 // iops
+	Identifier                  string            // Instance Identifier
+	AllocatedStorage            float64           // allocated storage
+	Iops                        float64           // iops
+	Class                       string            // instance class, e.g. db.r5.large
+	Engine                      string            // database engine, e.g. mysql or aurora-postgresql
+	EngineVersion               string            // database engine version
+	ParameterGroups             []string          // names of the DB parameter groups of the instance
+	MultiAZ                     bool              // whether the instance is a Multi-AZ deployment
+	SubnetGroup                 string            // name of the DB subnet group of the instance
+	StorageType                 string            // storage type, e.g. gp2 or io1
+	LicenseModel                string            // license model, e.g. license-included
+	ReplicaSource               string            // identifier of the source instance if it is a read replica, an ARN for cross-region replicas
+	ResourceID                  string            // region-unique, immutable identifier of the instance (DbiResourceId)
+	MonitoringInterval          float64           // Enhanced Monitoring interval in seconds, 0 if disabled
+	PerformanceInsights         bool              // whether Performance Insights is enabled
+	Arn                         string            // ARN of the instance
+	Cluster                     string            // identifier of the cluster the instance is a member of, if any
+	KmsKeyID                    string            // ARN of the KMS key the storage is encrypted with, if encrypted
+	PerformanceInsightsKmsKeyID string            // ARN of the KMS key the Performance Insights data is encrypted with, if enabled
+	Tags                        map[string]string // tags of the instance by key
+}
+
+// DBEvent represents a single entry of the RDS event stream
+type DBEvent struct {
+	SourceIdentifier string    // identifier of the resource that emitted the event
+	SourceType       string    // db-instance, db-cluster, db-snapshot, ...
+	Categories       []string  // failover, failure, low storage, ...
+	Date             time.Time // time the event was emitted
+	Message          string    // event message
+}
+
+// DBCluster represents a particular RDS cluster
+type DBCluster struct {
+	Identifier string // Cluster Identifier
+	Engine     string // database engine, e.g. aurora-mysql or docdb
+	Arn        string // ARN of the cluster
+	KmsKeyID   string // ARN of the KMS key the storage is encrypted with, if encrypted
+}
+
+// EventSubscription represents an RDS event notification subscription
+type EventSubscription struct {
+	Name        string   // subscription name
+	Status      string   // creating, active, no-permission, topic-not-exist, ...
+	Enabled     bool     // whether the subscription is enabled
+	SourceType  string   // source type the subscription applies to, all types if empty
+	Categories  []string // event categories, all categories if empty
+	SourceIds   []string // source identifiers, all sources of the type if empty
+	SnsTopicArn string   // topic the notifications are sent to
+}
+
+// DBProxy represents a particular RDS Proxy
+type DBProxy struct {
+	Name              string  // proxy name
+	Status            string  // available, creating, incompatible-network, ...
+	EngineFamily      string  // MYSQL or POSTGRESQL
+	IdleClientTimeout float64 // seconds a client connection can be idle before it is closed
+	RequireTLS        bool    // whether connections to the proxy must use TLS
+}
+
+// DBProxyTargetGroup represents a target group of an RDS Proxy
+type DBProxyTargetGroup struct {
+	ProxyName                 string  // proxy the target group belongs to
+	Name                      string  // target group name
+	Status                    string  // target group status
+	IsDefault                 bool    // whether this is the default target group of the proxy
+	MaxConnectionsPercent     float64 // connection pool size as percent of max_connections
+	MaxIdleConnectionsPercent float64 // idle connections kept as percent of max_connections
+}
+
+// DBProxyTarget represents an instance or cluster behind an RDS Proxy target group
+type DBProxyTarget struct {
+	ProxyName       string // proxy the target belongs to
+	TargetGroupName string // target group the target belongs to
+	RdsResourceId   string // identifier of the instance or cluster
+	Type            string // RDS_INSTANCE, RDS_SERVERLESS_ENDPOINT or TRACKED_CLUSTER
+	State           string // REGISTERING, AVAILABLE or UNAVAILABLE
+	Reason          string // reason for an UNAVAILABLE state
+}
+
+// DBEngineVersion represents an engine version of the RDS catalog
+type DBEngineVersion struct {
+	Engine         string          // database engine
+	Version        string          // engine version
+	Family         string          // parameter group family, the major line of the version
+	Status         string          // available or deprecated
+	UpgradeTargets []UpgradeTarget // versions an instance of this version can be upgraded to
+}
+
+// UpgradeTarget represents a version an engine version can be upgraded to
+type UpgradeTarget struct {
+	Version               string // engine version of the target
+	IsMajorVersionUpgrade bool   // whether the upgrade crosses a major version
+}
+
+// DBParameterGroup represents a DB parameter group
+type DBParameterGroup struct {
+	Name   string // parameter group name
+	Family string // parameter group family, e.g. postgres12
+}
+
+// DBParameter represents a parameter of a DB parameter group or of the engine defaults
+type DBParameter struct {
+	Name     string // parameter name
+	Value    string // parameter value, empty if not set
+	Source   string // engine-default, system or user
+	DataType string // string, integer, boolean, ...
+}
+
+// DBLogFile represents a log file of an RDS instance
+type DBLogFile struct {
+	Name        string    // log file name, e.g. error/postgresql.log.2020-10-01-12
+	Size        float64   // size in bytes
+	LastWritten time.Time // time the file was last written to
+}
+
+// DBLogFilePortion represents a portion of a log file downloaded from an RDS instance
+type DBLogFilePortion struct {
+	Data                  string // log lines of the portion
+	Marker                string // where the next portion starts
+	AdditionalDataPending bool   // whether there is more data after the marker
+}
+
+// ExportTask represents a snapshot export to S3
+type ExportTask struct {
+	Identifier         string    // export task identifier
+	SourceArn          string    // ARN of the exported snapshot
+	S3Bucket           string    // bucket the snapshot is exported to
+	Status             string    // STARTING, IN_PROGRESS, COMPLETE, CANCELING, CANCELED or FAILED
+	PercentProgress    float64   // progress of the export in percent
+	TotalExtractedData float64   // extracted data in bytes
+	StartTime          time.Time // time the export started, zero if not started yet
+	EndTime            time.Time // time the export ended, zero if still running
+	FailureCause       string    // reason of a failed export
+}
+
+// DBSubnetGroup represents a DB subnet group
+type DBSubnetGroup struct {
+	Name    string   // subnet group name
+	Status  string   // subnet group status, e.g. Complete
+	VpcId   string   // VPC of the subnet group
+	Subnets []Subnet // subnets of the subnet group
+}
+
+// Subnet represents a subnet of a DB subnet group
+type Subnet struct {
+	Identifier       string // subnet identifier
+	AvailabilityZone string // availability zone of the subnet
+	Status           string // Active or not
+}
+
+// Range represents a range of valid values
+type Range struct {
+	From float64 // lower bound
+	To   float64 // upper bound
+}
+
+// ValidStorageOptions represents the storage an RDS instance can be modified to, for one storage type
+type ValidStorageOptions struct {
+	StorageType        string  // gp2, io1, standard, ...
+	StorageSize        []Range // valid storage sizes in bytes
+	ProvisionedIops    []Range // valid provisioned iops, empty for storage types without provisioned iops
+	IopsToStorageRatio []Range // valid ratios of provisioned iops to storage in GiB
+}
+
+// OrderableDBInstanceOption represents an orderable combination of engine, version, class and storage
+type OrderableDBInstanceOption struct {
+	Class         string // instance class
+	Engine        string // database engine
+	EngineVersion string // database engine version
+	LicenseModel  string // license model
+	StorageType   string // storage type
+}
+
+// SourceRegion represents a region the configured region can replicate from
+type SourceRegion struct {
+	Name     string // region name
+	Endpoint string // RDS endpoint of the region
+	Status   string // status of the source region
+}
+
+// MetricQuery represents a CloudWatch metric statistic of a single resource
+type MetricQuery struct {
+	ID             string  // identifier of the query, unique per request
+	Namespace      string  // CloudWatch namespace, e.g. AWS/RDS
+	MetricName     string  // CloudWatch metric name, e.g. CPUUtilization
+	DimensionName  string  // dimension identifying the resource, e.g. DBInstanceIdentifier
+	DimensionValue string  // identifier of the resource
+	Statistic      string  // statistic, e.g. Average, Maximum or p99
+	Period         float64 // period in seconds
+}
+
+// MetricResult represents the datapoints returned for a MetricQuery
+type MetricResult struct {
+	ID         string      // identifier of the query
+	Timestamps []time.Time // timestamps of the datapoints, newest first
+	Values     []float64   // values of the datapoints, newest first
+}
+
+// ResourceMetric represents the datapoints of a Performance Insights metric, for one combination of dimensions
+type ResourceMetric struct {
+	Metric     string            // metric name, e.g. db.load.avg
+	Dimensions map[string]string // dimensions of the group the metric is broken down by, empty if not grouped
+	Timestamps []time.Time       // timestamps of the datapoints
+	Values     []float64         // values of the datapoints
+}
+
+// ReservedDBInstancesOffering represents a reserved DB instance offering
+type ReservedDBInstancesOffering struct {
+	ID                 string  // offering identifier
+	Class              string  // instance class
+	ProductDescription string  // engine and license, e.g. mysql or oracle-se2(li)
+	MultiAZ            bool    // whether the offering is for Multi-AZ deployments
+	OfferingType       string  // No Upfront, Partial Upfront or All Upfront
+	Duration           float64 // term in seconds
+	FixedPrice         float64 // upfront price in dollars
+	UsagePrice         float64 // hourly usage price in dollars
+	RecurringHourly    float64 // hourly recurring charges in dollars
+}
+
+// ReservedDBInstance represents a purchased reserved DB instance
+type ReservedDBInstance struct {
+	ID                 string  // reservation identifier
+	Class              string  // instance class
+	ProductDescription string  // engine and license, e.g. mysql or oracle-se2(li)
+	MultiAZ            bool    // whether the reservation is for Multi-AZ deployments
+	Count              float64 // number of instances reserved
+	State              string  // state of the reservation, e.g. active or retired
+}
+
+// HealthEvent represents an AWS Health event
+type HealthEvent struct {
+	Arn          string    // event ARN
+	TypeCode     string    // event type code, e.g. AWS_RDS_MAINTENANCE_SCHEDULED
+	TypeCategory string    // issue, scheduledChange or accountNotification
+	Region       string    // region of the event
+	Status       string    // open, upcoming or closed
+	StartTime    time.Time // start of the event
+	EndTime      time.Time // end of the event, zero if not known
+	Entities     []string  // identifiers of the affected resources
+}
+
+// RecoveryPoint represents an AWS Backup recovery point of a resource
+type RecoveryPoint struct {
+	Arn          string    // recovery point ARN
+	Vault        string    // name of the backup vault
+	Status       string    // COMPLETED, PARTIAL, DELETING or EXPIRED
+	CreationDate time.Time // creation of the recovery point
+	Size         float64   // size in bytes
+}
+
+// BackupJob represents an AWS Backup job of a resource
+type BackupJob struct {
+	ID           string    // backup job identifier
+	State        string    // e.g. RUNNING, COMPLETED or FAILED
+	CreationDate time.Time // creation of the job
+}
+
+// DBSnapshot represents a manual or automated snapshot of an RDS instance or cluster
+type DBSnapshot struct {
+	Identifier string // snapshot identifier
+	Cluster    bool   // whether it is a cluster snapshot
+	KmsKeyID   string // ARN of the KMS key the snapshot is encrypted with, if encrypted
+}
+
+// KMSKey represents the state of a KMS key
+type KMSKey struct {
+	Arn             string    // key ARN
+	State           string    // Enabled, Disabled, PendingDeletion, PendingImport or Unavailable
+	Manager         string    // AWS or CUSTOMER
+	DeletionDate    time.Time // scheduled deletion of the key, zero if not pending deletion
+	RotationEnabled bool      // whether automatic rotation of the key material is enabled
 }